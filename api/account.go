@@ -8,11 +8,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
 	"github.com/zjr71163356/simplebank/token"
+	"github.com/zjr71163356/simplebank/val"
 )
 
 type CreateAccountParams struct {
-	Currency string `json:"currency" binding:"required,oneof=USD EUR CAD JPY GBP"`
+	Currency string `json:"currency" binding:"required,account_currency"`
 }
 
 type GetAccountParams struct {
@@ -20,8 +22,16 @@ type GetAccountParams struct {
 }
 
 type GetAccountListParams struct {
-	PageId   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10" `
+	// Deprecated: use PageToken instead. When page_id is present the legacy
+	// offset pagination is used and a plain array of accounts is returned.
+	PageId    *int32 `form:"page_id" binding:"omitempty,min=1"`
+	PageSize  int32  `form:"page_size" binding:"omitempty,min=1,max=100"`
+	PageToken string `form:"page_token"`
+}
+
+type GetAccountListResponse struct {
+	Accounts      []db.Account `json:"accounts"`
+	NextPageToken string       `json:"next_page_token"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -34,12 +44,25 @@ func (server *Server) createAccount(ctx *gin.Context) {
 	if !ok {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("invalid token payload")))
 	}
-	account, err := server.store.CreateAccount(ctx, db.CreateAccountParams{
-		Owner:    payload.Username,
-		Currency: reqData.Currency,
-		Balance:  0,
+	idempotency, err := idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	account, err := server.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    payload.Username,
+			Currency: reqData.Currency,
+			Balance:  0,
+		},
+		Idempotency: idempotency,
+		Audit:       auditParams(ctx, payload.Username),
 	})
 	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		//foreign_key_violation
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -73,7 +96,7 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	if account.Owner != payload.Username {
+	if !canAccessOwner(ctx, routePermissions, payload, account.Owner) {
 		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("account doesn't belong to the user")))
 		return
 	}
@@ -93,11 +116,59 @@ func (server *Server) getAccountList(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("invalid token payload")))
 	}
 
+	if reqData.PageId != nil {
+		server.getAccountListByOffset(ctx, payload.Username, reqData)
+		return
+	}
+
+	scope := "list_accounts:" + payload.Username
+	afterCreatedAt, afterID, err := server.pageTokens.Keyset(reqData.PageToken, scope)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pageSize := pagination.PageSize(reqData.PageSize)
+	accountList, err := server.store.ListAccountsAfter(ctx, db.ListAccountsAfterParams{
+		Owner:          payload.Username,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          pageSize + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := GetAccountListResponse{Accounts: accountList}
+	if len(accountList) > int(pageSize) {
+		rsp.Accounts = accountList[:pageSize]
+		last := rsp.Accounts[len(rsp.Accounts)-1]
+		rsp.NextPageToken, err = server.pageTokens.Encode(pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, scope)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// getAccountListByOffset serves the deprecated page_id pagination.
+func (server *Server) getAccountListByOffset(ctx *gin.Context, owner string, reqData GetAccountListParams) {
+	if err := val.ValidatePageSize(reqData.PageSize); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if reqData.PageToken != "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("page_token can't be combined with page_id")))
+		return
+	}
+
 	var accountList []db.Account
 	accountList, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
 		Limit:  reqData.PageSize,
-		Offset: (reqData.PageId - 1) * reqData.PageSize,
-		Owner:  payload.Username,
+		Offset: (*reqData.PageId - 1) * reqData.PageSize,
+		Owner:  owner,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 	mockdb "github.com/zjr71163356/simplebank/db/mock"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/rbac"
 	"github.com/zjr71163356/simplebank/token"
 	"github.com/zjr71163356/simplebank/utils"
 )
@@ -84,6 +85,33 @@ func TestGetAccount(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name:      "BankerReadsOtherAccount",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, "banker_user", time.Minute, token.WithRoles(rbac.RoleBanker))
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:      "ReadOnlyToken",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, account.Owner, time.Minute, token.WithScopes(rbac.ScopeAccountsRead))
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 		{
 			name:      "UnauthorizedUser",
 			accountID: account.ID,
@@ -196,13 +224,76 @@ func TestCreateAccount(t *testing.T) {
 					Currency: account.Currency,
 					Balance:  0,
 				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(db.CreateAccountTxParams{
+					CreateAccountParams: arg,
+					Audit:               &db.AuditParams{Actor: account.Owner},
+				})).Times(1).Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "WithIdempotencyKey",
+			body: gin.H{
+				"currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, account.Owner, time.Minute)
+				request.Header.Set(idempotencyKeyHeader, "create-account-key")
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:    account.Owner,
+						Currency: account.Currency,
+						Balance:  0,
+					},
+					Idempotency: &db.IdempotencyParams{
+						Username: account.Owner,
+						Key:      "create-account-key",
+					},
+					Audit: &db.AuditParams{Actor: account.Owner},
+				}
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "IdempotencyKeyConflict",
+			body: gin.H{
+				"currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, account.Owner, time.Minute)
+				request.Header.Set(idempotencyKeyHeader, "create-account-key")
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrIdempotencyKeyConflict)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ReadOnlyToken",
+			body: gin.H{
+				"currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, account.Owner, time.Minute, token.WithScopes(rbac.ScopeAccountsRead))
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "NoAuthorization",
 			body: gin.H{
@@ -211,7 +302,7 @@ func TestCreateAccount(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -226,7 +317,7 @@ func TestCreateAccount(t *testing.T) {
 				addAuthorization(t, request, "invalid_format", tokenMaker, account.Owner, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -241,7 +332,7 @@ func TestCreateAccount(t *testing.T) {
 				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, account.Owner, -time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -256,7 +347,7 @@ func TestCreateAccount(t *testing.T) {
 				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, account.Owner, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -271,7 +362,7 @@ func TestCreateAccount(t *testing.T) {
 				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, account.Owner, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -287,7 +378,7 @@ func TestCreateAccount(t *testing.T) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				pqErr := &pq.Error{Code: pq.ErrorCode("23505")}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, pqErr)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, pqErr)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusForbidden, recorder.Code)
@@ -303,7 +394,7 @@ func TestCreateAccount(t *testing.T) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				pqErr := &pq.Error{Code: pq.ErrorCode("23503")}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, pqErr)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, pqErr)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusForbidden, recorder.Code)
@@ -371,8 +462,10 @@ func TestGetAccountList(t *testing.T) {
 	}
 
 	type Query struct {
-		pageID   int32
-		pageSize int32
+		pageID    int32
+		pageSize  int32
+		pageToken string
+		keyset    bool
 	}
 
 	testCases := []struct {
@@ -499,6 +592,81 @@ func TestGetAccountList(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "KeysetFirstPage",
+			query: Query{
+				pageSize: 5,
+				keyset:   true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, username, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsAfterParams{
+					Owner: username,
+					Limit: 6,
+				}
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(append(append([]db.Account{}, accounts...), randomAccount(username)), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyMatchAccountPage(t, recorder.Body, accounts)
+				require.NotEmpty(t, rsp.NextPageToken)
+			},
+		},
+		{
+			name: "KeysetLastPage",
+			query: Query{
+				pageSize: 5,
+				keyset:   true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, username, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rsp := requireBodyMatchAccountPage(t, recorder.Body, accounts)
+				require.Empty(t, rsp.NextPageToken)
+			},
+		},
+		{
+			name: "InvalidPageToken",
+			query: Query{
+				pageSize:  5,
+				pageToken: "invalid",
+				keyset:    true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, username, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "KeysetInvalidPageSize",
+			query: Query{
+				pageSize: 101,
+				keyset:   true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, username, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -522,8 +690,13 @@ func TestGetAccountList(t *testing.T) {
 
 				// 添加查询参数
 				q := request.URL.Query()
-				q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
+				if !tc.query.keyset {
+					q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
+				}
 				q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+				if tc.query.pageToken != "" {
+					q.Add("page_token", tc.query.pageToken)
+				}
 				request.URL.RawQuery = q.Encode()
 
 				tc.setupAuth(t, request, server.tokenMaker)
@@ -543,3 +716,13 @@ func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Ac
 	require.NoError(t, err)
 	require.Equal(t, accounts, gotAccounts)
 }
+
+func requireBodyMatchAccountPage(t *testing.T, body *bytes.Buffer, accounts []db.Account) GetAccountListResponse {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	var rsp GetAccountListResponse
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+	require.Equal(t, accounts, rsp.Accounts)
+	return rsp
+}
@@ -0,0 +1,15 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+)
+
+// auditParams describes the caller of the current request for the audit log
+func auditParams(ctx *gin.Context, actor string) *db.AuditParams {
+	return &db.AuditParams{
+		Actor:     actor,
+		ClientIP:  ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/val"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyParams returns nil when the client did not send an Idempotency-Key header
+func idempotencyParams(ctx *gin.Context, username string) (*db.IdempotencyParams, error) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+	if err := val.ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	return &db.IdempotencyParams{Username: username, Key: key}, nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
@@ -8,20 +9,32 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/token"
 	"github.com/zjr71163356/simplebank/utils"
 )
 
 func newTestServer(t *testing.T, store db.Store) (*Server, error) {
 	config := utils.Config{
 		TokenSymmetricKey:   utils.RandomString(32),
+		PageTokenKey:        utils.RandomString(32),
 		AccessTokenDuration: time.Minute,
 	}
-	server, err := NewServer(config, store)
+	// 测试中不查询撤销表，避免每个用例都要为mock store设置期望
+	server, err := NewServer(config, store, revocationStub{})
 	require.NoError(t, err)
 
 	return server, nil
 }
 
+// revocationStub treats every token of the listed users as revoked
+type revocationStub map[string]bool
+
+func (stub revocationStub) IsRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	return stub[payload.Username], nil
+}
+
+func (stub revocationStub) Purge() {}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zjr71163356/simplebank/rbac"
+	"github.com/zjr71163356/simplebank/revocation"
 	"github.com/zjr71163356/simplebank/token"
 )
 
@@ -16,7 +18,7 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
+func authMiddleWare(tokenMaker token.Maker, revocations revocation.Checker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeaderValue := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeaderValue) == 0 {
@@ -45,6 +47,20 @@ func authMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
+		if err := payload.VerifyAccess(); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		revoked, err := revocations.IsRevoked(ctx, payload)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if revoked {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(revocation.ErrTokenRevoked))
+			return
+		}
 
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
@@ -52,3 +68,26 @@ func authMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
 	}
 
 }
+
+// permissionMiddleWare checks the token set by authMiddleWare against the
+// rule of the matched route
+func permissionMiddleWare(permissions rbac.Table) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if err := permissions.Authorize(routeKey(ctx), payload); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// canAccessOwner reports whether the caller may act on resources of owner
+// at the matched route
+func canAccessOwner(ctx *gin.Context, permissions rbac.Table, payload *token.Payload, owner string) bool {
+	return permissions.CanAccessOwner(routeKey(ctx), payload, owner)
+}
+
+func routeKey(ctx *gin.Context) string {
+	return ctx.Request.Method + " " + ctx.FullPath()
+}
@@ -13,8 +13,8 @@ import (
 	"github.com/zjr71163356/simplebank/token"
 )
 
-func addAuthorization(t *testing.T, request *http.Request, authorizationType string, tokenMaker token.Maker, username string, duration time.Duration) {
-	token, _, err := tokenMaker.CreateToken(username, duration)
+func addAuthorization(t *testing.T, request *http.Request, authorizationType string, tokenMaker token.Maker, username string, duration time.Duration, options ...token.PayloadOption) {
+	token, _, err := tokenMaker.CreateToken(username, duration, options...)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	authorizationValue := fmt.Sprintf("%s %s", authorizationType, token)
@@ -104,6 +104,36 @@ func TestMiddleware(t *testing.T) {
 				require.Contains(t, errorBody.Error, "token is expired") // Adjust expected message based on token verification logic
 			},
 		},
+		{
+			name: "RevokedToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, "bearer", tokenMaker, "revoked_user", time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				var errorBody struct {
+					Error string `json:"error"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &errorBody)
+				require.NoError(t, err)
+				require.Contains(t, errorBody.Error, "token has been revoked")
+			},
+		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, authorizationTypeBearer, tokenMaker, "user", time.Minute, token.WithTokenType(token.TokenTypeRefresh))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				var errorBody struct {
+					Error string `json:"error"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &errorBody)
+				require.NoError(t, err)
+				require.Contains(t, errorBody.Error, token.ErrNotAccessToken.Error())
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -114,7 +144,7 @@ func TestMiddleware(t *testing.T) {
 			require.NoError(t, err)
 
 			authUrl := "/auth"
-			server.router.GET(authUrl, authMiddleWare(server.tokenMaker), func(ctx *gin.Context) {
+			server.router.GET(authUrl, authMiddleWare(server.tokenMaker, revocationStub{"revoked_user": true}), func(ctx *gin.Context) {
 				ctx.JSON(http.StatusOK, nil)
 			})
 
@@ -0,0 +1,25 @@
+package api
+
+import "github.com/zjr71163356/simplebank/rbac"
+
+// routePermissions lists every route behind authMiddleWare, keyed by method
+// and route pattern
+var routePermissions = rbac.Table{
+	"POST /CreateAccount": {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeAccountsWrite,
+	},
+	"GET /GetAccount/:id": {
+		Roles:         rbac.AllRoles,
+		Scope:         rbac.ScopeAccountsRead,
+		AnyOwnerRoles: rbac.StaffRoles,
+	},
+	"GET /GetAccountList": {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeAccountsRead,
+	},
+	"POST /CreateTransfer": {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeTransfersWrite,
+	},
+}
@@ -7,26 +7,44 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
+	"github.com/zjr71163356/simplebank/revocation"
 	"github.com/zjr71163356/simplebank/token"
 	"github.com/zjr71163356/simplebank/utils"
 )
 
 type Server struct {
-	config     utils.Config
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config      utils.Config
+	store       db.Store
+	tokenMaker  token.Maker
+	pageTokens  *pagination.PageTokenCodec
+	revocations revocation.Checker
+	router      *gin.Engine
 }
 
-func NewServer(config utils.Config, store db.Store) (*Server, error) {
-	maker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+// NewServer creates a server checking tokens against revocations, which is
+// shared with the other servers of the process so a revocation is seen by all
+func NewServer(config utils.Config, store db.Store, revocations revocation.Checker) (*Server, error) {
+	maker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("fail to create token:%w", err)
 	}
-	server := &Server{config: config, store: store, tokenMaker: maker}
+	pageTokens, err := pagination.NewPageTokenCodec(config.PageTokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create page token codec, check PAGE_TOKEN_KEY:%w", err)
+	}
+	server := &Server{
+		config:      config,
+		store:       store,
+		tokenMaker:  maker,
+		pageTokens:  pageTokens,
+		revocations: revocations,
+	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", vaildatorCurrency)
+		v.RegisterValidation("account_currency", vaildatorAccountCurrency)
+		v.RegisterValidation("username", vaildatorUsername)
 	}
 
 	server.setupRouter()
@@ -37,13 +55,16 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 func (server *Server) setupRouter() {
 	router := gin.Default()
 
-	authRouter := router.Group("/").Use(authMiddleWare(server.tokenMaker))
+	authRouter := router.Group("/").Use(
+		authMiddleWare(server.tokenMaker, server.revocations),
+		permissionMiddleWare(routePermissions),
+	)
 	authRouter.POST("/CreateAccount", server.createAccount)
 	authRouter.GET("/GetAccount/:id", server.getAccount)
 	authRouter.GET("/GetAccountList", server.getAccountList)
+	authRouter.POST("/CreateTransfer", server.createTransfer)
 
 	router.POST("/RenewToken", server.RenewToken)
-	router.POST("/CreateTransfer", server.createTransfer)
 	router.POST("/User/Create", server.createUser)
 	router.POST("/User/Login", server.loginUser)
 	server.router = router
@@ -61,3 +82,8 @@ func (server *Server) Start(address string) error {
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
+
+// ReloadTokenKeys swaps in the token keys of the reloaded config
+func (server *Server) ReloadTokenKeys(config utils.Config) error {
+	return token.ReloadKeys(server.tokenMaker, config)
+}
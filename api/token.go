@@ -1,20 +1,30 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/rbac"
+	"github.com/zjr71163356/simplebank/revocation"
+	"github.com/zjr71163356/simplebank/token"
 )
 
 type RenewTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string   `json:"refresh_token" binding:"required"`
+	Scopes       []string `json:"scopes"`
 }
 
 type NewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
 func (server *Server) RenewToken(ctx *gin.Context) {
@@ -30,6 +40,17 @@ func (server *Server) RenewToken(ctx *gin.Context) {
 		return
 	}
 
+	//修改密码或撤销全部会话之前签发的刷新token不能再续期
+	revoked, err := server.revocations.IsRevoked(ctx, payload)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if revoked {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(revocation.ErrTokenRevoked))
+		return
+	}
+
 	session, err := server.store.GetSession(ctx, payload.Id)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -60,15 +81,75 @@ func (server *Server) RenewToken(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, payload, err := server.tokenMaker.CreateToken(payload.Username, server.config.AccessTokenDuration)
+	//角色以数据库为准，降级或禁用的用户不能靠续期保留原有权限
+	user, err := server.store.GetUser(ctx, session.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if user.DisabledAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("user is disabled")))
+		return
+	}
+
+	//续期不能扩大原token的权限，只能收窄scope
+	scopes, err := rbac.GrantScopes(user.Role, reqData.Scopes, payload.Scopes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	claims := []token.PayloadOption{
+		token.WithRoles(user.Role),
+		token.WithScopes(scopes...),
+	}
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(payload.Username, server.config.AccessTokenDuration, claims...)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(payload.Username, server.config.RefreshTokenDuration, append(claims, token.WithTokenType(token.TokenTypeRefresh))...)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	//轮换刷新token：旧会话标记为已消费，新会话继承同一个会话族
+	newSession, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           refreshPayload.Id,
+			Username:     payload.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    refreshPayload.ExpiredAt,
+			AccessTokenID: uuid.NullUUID{
+				UUID:  accessPayload.Id,
+				Valid: true,
+			},
+		},
+		Audit: auditParams(ctx, payload.Username),
+	})
 	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenReused) {
+			server.revocations.Purge()
+		}
+		if errors.Is(err, db.ErrRefreshTokenReused) || errors.Is(err, db.ErrSessionBlocked) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	rsp := NewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: payload.ExpiredAt,
+		SessionID:             newSession.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
 	}
 	ctx.JSON(http.StatusAccepted, rsp)
 
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	mockdb "github.com/zjr71163356/simplebank/db/mock"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/rbac"
 	"github.com/zjr71163356/simplebank/token"
 	"github.com/zjr71163356/simplebank/utils"
 )
@@ -23,6 +24,7 @@ import (
 
 func TestRenewTokenAPI(t *testing.T) {
 	user, _ := randomUser(t)
+	user.Role = rbac.RoleDepositor
 	accessTokenDuration := time.Minute
 
 	// 创建随机会话的辅助函数
@@ -36,14 +38,20 @@ func TestRenewTokenAPI(t *testing.T) {
 			IsBlocked:    isBlocked,
 			ExpiresAt:    expiresAt,
 			CreatedAt:    time.Now(),
+			FamilyID:     uuid.New(),
 		}
 	}
 
+	// 创建一个真实的 PasetoMaker 用于生成初始刷新令牌，并校验续期后的令牌
+	tokenMaker, err := token.NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name          string
 		body          gin.H
 		setupAuth     func(t *testing.T, tokenMaker token.Maker) string // 返回用于请求的刷新令牌
 		buildStubs    func(store *mockdb.MockStore, refreshToken string, refreshPayload *token.Payload, session db.Session)
+		revocations   revocationStub
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -63,6 +71,19 @@ func TestRenewTokenAPI(t *testing.T) {
 					GetSession(gomock.Any(), gomock.Eq(refreshPayload.Id)).
 					Times(1).
 					Return(validSession, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateSessionTxParams) (db.Session, error) {
+						require.Equal(t, validSession.ID, arg.SessionID)
+						require.Equal(t, refreshPayload.Username, arg.NewSession.Username)
+						require.NotEqual(t, refreshTokenString, arg.NewSession.RefreshToken)
+						return db.Session{ID: arg.NewSession.ID, FamilyID: validSession.FamilyID}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusAccepted, recorder.Code)
@@ -72,6 +93,125 @@ func TestRenewTokenAPI(t *testing.T) {
 				require.NoError(t, err)
 				require.NotEmpty(t, rsp.AccessToken)
 				require.WithinDuration(t, time.Now().Add(accessTokenDuration), rsp.AccessTokenExpiresAt, time.Second)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.NotEqual(t, uuid.Nil, rsp.SessionID)
+			},
+		},
+		{
+			name: "DemotedRole", // 续期按数据库中的当前角色签发，不沿用刷新令牌中的角色
+			body: gin.H{},
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) string {
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, time.Hour, token.WithRoles(rbac.RoleAdmin))
+				require.NoError(t, err)
+				return refreshToken
+			},
+			buildStubs: func(store *mockdb.MockStore, refreshTokenString string, refreshPayload *token.Payload, session db.Session) {
+				validSession := randomSession(refreshTokenString, refreshPayload.Username, false, time.Now().Add(time.Hour))
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(refreshPayload.Id)).
+					Times(1).
+					Return(validSession, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateSessionTxParams) (db.Session, error) {
+						return db.Session{ID: arg.NewSession.ID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var rsp NewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				for _, renewed := range []string{rsp.AccessToken, rsp.RefreshToken} {
+					payload, err := tokenMaker.VerifyToken(renewed)
+					require.NoError(t, err)
+					require.Equal(t, []string{rbac.RoleDepositor}, payload.Roles)
+				}
+			},
+		},
+		{
+			name: "UserDisabled",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) string {
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, time.Hour)
+				require.NoError(t, err)
+				return refreshToken
+			},
+			buildStubs: func(store *mockdb.MockStore, refreshTokenString string, refreshPayload *token.Payload, session db.Session) {
+				validSession := randomSession(refreshTokenString, refreshPayload.Username, false, time.Now().Add(time.Hour))
+				disabledUser := user
+				disabledUser.DisabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(refreshPayload.Id)).
+					Times(1).
+					Return(validSession, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(disabledUser, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "user is disabled")
+			},
+		},
+		{
+			name: "RefreshTokenReused",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) string {
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, time.Hour)
+				require.NoError(t, err)
+				return refreshToken
+			},
+			buildStubs: func(store *mockdb.MockStore, refreshTokenString string, refreshPayload *token.Payload, session db.Session) {
+				consumedSession := randomSession(refreshTokenString, refreshPayload.Username, false, time.Now().Add(time.Hour))
+				consumedSession.ConsumedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(refreshPayload.Id)).
+					Times(1).
+					Return(consumedSession, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, db.ErrRefreshTokenReused)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.ErrRefreshTokenReused.Error())
+			},
+		},
+		{
+			name: "RefreshTokenRevoked", // 修改密码之后，之前签发的刷新token不能再续期
+			body: gin.H{},
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) string {
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, time.Hour)
+				require.NoError(t, err)
+				return refreshToken
+			},
+			buildStubs: func(store *mockdb.MockStore, refreshTokenString string, refreshPayload *token.Payload, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RotateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			revocations: revocationStub{user.Username: true},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
@@ -210,12 +350,6 @@ func TestRenewTokenAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 
-			// 创建一个真实的 PasetoMaker 用于生成初始刷新令牌
-			// 使用固定密钥以便可重现，或者使用随机密钥以保证唯一性
-			symmetricKey := utils.RandomString(32)
-			tokenMaker, err := token.NewPasetoMaker(symmetricKey)
-			require.NoError(t, err)
-
 			// 使用真实的 token maker 获取刷新令牌字符串（因为 setupAuth 需要 token.Maker）
 			var refreshTokenString string
 			if tc.setupAuth != nil {
@@ -245,9 +379,13 @@ func TestRenewTokenAPI(t *testing.T) {
 
 			// 替换 tokenMaker 以便我们可以控制它的行为
 			server.tokenMaker = tokenMaker
+			if tc.revocations != nil {
+				server.revocations = tc.revocations
+			}
 
 			// 确保 AccessTokenDuration 与测试设置一致
 			server.config.AccessTokenDuration = accessTokenDuration
+			server.config.RefreshTokenDuration = time.Hour
 
 			recorder := httptest.NewRecorder()
 
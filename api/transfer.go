@@ -13,7 +13,7 @@ import (
 
 type CreateTransfer struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
 	Amount        int64  `json:"amount" binding:"required,min=1"`
 	Currency      string `json:"currency" binding:"required,currency"`
 }
@@ -24,44 +24,70 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	payload, ok := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if !ok {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("invalid token payload")))
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	fromAccount, valid := server.validAccount(ctx, reqData.FromAccountID, reqData.Currency)
+	if !valid {
+		return
+	}
+
+	if fromAccount.Owner != payload.Username {
+		err := fmt.Errorf("account %d does not belong to user %s", fromAccount.ID, payload.Username)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
 	}
-	isVaild, err := server.validAccount(ctx, reqData.FromAccountID, reqData.Currency, payload.Username)
-	if !isVaild {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+
+	if _, valid := server.validAccount(ctx, reqData.ToAccountID, reqData.Currency); !valid {
 		return
 	}
-	account, err := server.store.CreateTransfer(ctx, db.CreateTransferParams{
+
+	idempotency, err := idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
 		FromAccountID: reqData.FromAccountID,
 		ToAccountID:   reqData.ToAccountID,
 		Amount:        reqData.Amount,
+		Idempotency:   idempotency,
+		Audit:         auditParams(ctx, payload.Username),
 	})
-
 	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrSystemAccount):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrIdempotencyKeyConflict):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, result)
 
 }
-func (server *Server) validAccount(ctx *gin.Context, accountId int64, curreny string, username string) (bool, error) {
+
+// validAccount writes the error response itself and reports whether the handler may continue
+func (server *Server) validAccount(ctx *gin.Context, accountId int64, curreny string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return false, fmt.Errorf("account %d does not exist", accountId)
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("account %d does not exist", accountId)))
+			return account, false
 		}
-		return false, fmt.Errorf("failed to get account %d: %w", accountId, err)
-	}
-
-	if account.Owner != username {
-		return false, fmt.Errorf("account %d does not belong to user %s", accountId, username)
+		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to get account %d: %w", accountId, err)))
+		return account, false
 	}
 
 	if account.Currency != curreny {
-		return false, fmt.Errorf("account %d has a different currency %s", accountId, account.Currency)
+		err := fmt.Errorf("account %d has a different currency %s", accountId, account.Currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return account, false
 	}
-	return true, nil
+	return account, true
 
 }
@@ -10,11 +10,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/rbac"
+	"github.com/zjr71163356/simplebank/token"
 	"github.com/zjr71163356/simplebank/utils"
 )
 
 type CreateUserParams struct {
-	Username string `json:"username" binding:"required,alphanum"`
+	Username string `json:"username" binding:"required,username"`
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
@@ -26,11 +28,13 @@ type userResponse struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
 }
 
 type loginUserRequest struct {
-	Username string `json:"username" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string   `json:"username" binding:"required,alphanum"`
+	Password string   `json:"password" binding:"required,min=6"`
+	Scopes   []string `json:"scopes"`
 }
 
 type loginUserResponse struct {
@@ -49,6 +53,7 @@ func newUserResponse(user db.User) userResponse {
 		Email:             user.Email,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
+		Role:              user.Role,
 	}
 }
 
@@ -74,27 +79,45 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
+	if user.DisabledAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("user is disabled")))
+		return
+	}
+
+	scopes, err := rbac.GrantScopes(user.Role, reqData.Scopes, nil)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-	accessToken, payload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	accessToken, payload, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration, token.WithRoles(user.Role), token.WithScopes(scopes...))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.RefreshTokenDuration)
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.RefreshTokenDuration, token.WithRoles(user.Role), token.WithScopes(scopes...), token.WithTokenType(token.TokenTypeRefresh))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.Id,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    ctx.Request.UserAgent(),
-		ClientIp:     ctx.ClientIP(),
-		IsBlocked:    false,
-		ExpiresAt:    refreshPayload.ExpiredAt,
+	session, err := server.store.CreateSessionTx(ctx, db.CreateSessionTxParams{
+		CreateSessionParams: db.CreateSessionParams{
+			ID:           refreshPayload.Id,
+			Username:     user.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    refreshPayload.ExpiredAt,
+			FamilyID:     refreshPayload.Id,
+			AccessTokenID: uuid.NullUUID{
+				UUID:  payload.Id,
+				Valid: true,
+			},
+		},
+		Audit: auditParams(ctx, user.Username),
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -127,11 +150,14 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := server.store.CreateUser(ctx, db.CreateUserParams{
-		Username:       reqData.Username,
-		HashedPassword: HashedPassword,
-		FullName:       reqData.FullName,
-		Email:          reqData.Email,
+	user, err := server.store.CreateUserTx(ctx, db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       reqData.Username,
+			HashedPassword: HashedPassword,
+			FullName:       reqData.FullName,
+			Email:          reqData.Email,
+		},
+		Audit: auditParams(ctx, reqData.Username),
 	})
 
 	returnedUser := userResponse{
@@ -140,6 +166,7 @@ func (server *Server) createUser(ctx *gin.Context) {
 		Email:             user.Email,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
+		Role:              user.Role,
 	}
 	if err != nil {
 		//foreign_key_violation
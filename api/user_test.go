@@ -20,12 +20,12 @@ import (
 )
 
 type eqCreateUserMatcher struct {
-	arg      db.CreateUserParams
+	arg      db.CreateUserTxParams
 	password string
 }
 
 func (eqM eqCreateUserMatcher) Matches(x interface{}) bool {
-	v, ok := x.(db.CreateUserParams)
+	v, ok := x.(db.CreateUserTxParams)
 	if !ok {
 		return false
 	}
@@ -44,7 +44,7 @@ func (eqM eqCreateUserMatcher) String() string {
 	return fmt.Sprintf("is equal to arg: %v (%T) and  password: %v (%T)", eqM.arg, eqM.arg, eqM.password, eqM.password)
 }
 
-func NewEqCreateUserMatcher(arg db.CreateUserParams, password string) gomock.Matcher {
+func NewEqCreateUserMatcher(arg db.CreateUserTxParams, password string) gomock.Matcher {
 	return eqCreateUserMatcher{
 		arg:      arg,
 		password: password,
@@ -68,12 +68,15 @@ func TestGetUserAPI(t *testing.T) {
 				"password":  password,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				userInput := db.CreateUserParams{
-					Username: user.Username,
-					FullName: user.FullName,
-					Email:    user.Email,
+				userInput := db.CreateUserTxParams{
+					CreateUserParams: db.CreateUserParams{
+						Username: user.Username,
+						FullName: user.FullName,
+						Email:    user.Email,
+					},
+					Audit: &db.AuditParams{Actor: user.Username},
 				}
-				store.EXPECT().CreateUser(gomock.Any(), NewEqCreateUserMatcher(userInput, password)).Times(1).Return(user, nil)
+				store.EXPECT().CreateUserTx(gomock.Any(), NewEqCreateUserMatcher(userInput, password)).Times(1).Return(user, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -90,12 +93,15 @@ func TestGetUserAPI(t *testing.T) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 
-				userInput := db.CreateUserParams{
-					Username: user.Username,
-					FullName: user.FullName,
-					Email:    user.Email,
+				userInput := db.CreateUserTxParams{
+					CreateUserParams: db.CreateUserParams{
+						Username: user.Username,
+						FullName: user.FullName,
+						Email:    user.Email,
+					},
+					Audit: &db.AuditParams{Actor: user.Username},
 				}
-				store.EXPECT().CreateUser(gomock.Any(), NewEqCreateUserMatcher(userInput, password)).Times(1).Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().CreateUserTx(gomock.Any(), NewEqCreateUserMatcher(userInput, password)).Times(1).Return(db.User{}, sql.ErrConnDone)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
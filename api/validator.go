@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/zjr71163356/simplebank/utils"
+	"github.com/zjr71163356/simplebank/val"
 )
 
 var vaildatorCurrency validator.Func = func(fl validator.FieldLevel) bool {
@@ -11,3 +12,17 @@ var vaildatorCurrency validator.Func = func(fl validator.FieldLevel) bool {
 	}
 	return false
 }
+
+var vaildatorAccountCurrency validator.Func = func(fl validator.FieldLevel) bool {
+	if v, ok := fl.Field().Interface().(string); ok {
+		return utils.VaildatorAccountCurrency(v)
+	}
+	return false
+}
+
+var vaildatorUsername validator.Func = func(fl validator.FieldLevel) bool {
+	if v, ok := fl.Field().Interface().(string); ok {
+		return val.ValidateUsername(v) == nil
+	}
+	return false
+}
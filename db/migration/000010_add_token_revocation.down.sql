@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "access_token_id";
+
+DROP TABLE IF EXISTS "token_not_before";
+
+DROP TABLE IF EXISTS "revoked_tokens";
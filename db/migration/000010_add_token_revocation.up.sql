@@ -0,0 +1,23 @@
+CREATE TABLE "revoked_tokens" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "token_not_before" (
+  "username" varchar PRIMARY KEY,
+  "not_before" timestamptz NOT NULL
+);
+
+ALTER TABLE "revoked_tokens" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "token_not_before" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "revoked_tokens" ("expires_at");
+
+ALTER TABLE "sessions" ADD COLUMN "access_token_id" uuid;
+
+COMMENT ON COLUMN "token_not_before"."not_before" IS 'tokens of the user issued before this time are revoked';
+
+COMMENT ON COLUMN "sessions"."access_token_id" IS 'id of the access token issued together with the refresh token';
@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "disabled_at" timestamptz;
+
+COMMENT ON COLUMN "users"."disabled_at" IS 'set while the user is locked out by an admin';
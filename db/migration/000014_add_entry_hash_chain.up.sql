@@ -0,0 +1,9 @@
+ALTER TABLE "entries" ADD COLUMN "prev_hash" bytea;
+
+ALTER TABLE "entries" ADD COLUMN "hash" bytea;
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
+COMMENT ON COLUMN "entries"."prev_hash" IS 'hash of the previous entry of the account, null for the first entry of the chain';
+
+COMMENT ON COLUMN "entries"."hash" IS 'sha256 over the entry and prev_hash, null for entries written before the chain existed';
@@ -0,0 +1,11 @@
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'system');
+
+DELETE FROM "accounts" WHERE "owner" = 'system';
+
+DELETE FROM "users" WHERE "username" = 'system';
+
+DROP INDEX IF EXISTS "entries_ledger_transaction_id_idx";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "ledger_transaction_id";
+
+DROP TABLE IF EXISTS "ledger_transactions";
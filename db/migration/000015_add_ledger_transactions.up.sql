@@ -0,0 +1,40 @@
+CREATE TABLE "ledger_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "type" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "ledger_transactions" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transactions_type_check" CHECK ("type" IN ('deposit', 'withdrawal'));
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transactions_amount_positive" CHECK ("amount" > 0);
+
+CREATE INDEX ON "ledger_transactions" ("account_id", "created_at");
+
+COMMENT ON COLUMN "ledger_transactions"."type" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "ledger_transactions"."account_id" IS 'customer account funded or drawn down';
+
+COMMENT ON COLUMN "ledger_transactions"."amount" IS 'must be positive';
+
+ALTER TABLE "entries" ADD COLUMN "ledger_transaction_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("ledger_transaction_id") REFERENCES "ledger_transactions" ("id");
+
+CREATE INDEX ON "entries" ("ledger_transaction_id");
+
+-- 客户已经注册了同名用户时中止迁移，不能把它当成银行自己的用户
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM "users" WHERE "username" = 'system') THEN
+    RAISE EXCEPTION 'username system is reserved for the bank but is already registered, rename that user before migrating';
+  END IF;
+END $$;
+
+-- 银行自己的账户挂在system用户下，每种货币一个现金账户，按需创建。
+-- 密码为空且已停用，无法登录
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "disabled_at")
+VALUES ('system', '', 'SimpleBank', 'system@simplebank.invalid', now());
@@ -0,0 +1,20 @@
+UPDATE "entries" SET "ledger_transaction_id" = NULL
+WHERE "ledger_transaction_id" IN (SELECT "id" FROM "ledger_transactions" WHERE "type" = 'journal');
+
+DELETE FROM "ledger_transactions" WHERE "type" = 'journal';
+
+ALTER TABLE IF EXISTS "ledger_transactions" DROP CONSTRAINT IF EXISTS "ledger_transactions_account_check";
+
+ALTER TABLE IF EXISTS "ledger_transactions" DROP CONSTRAINT IF EXISTS "ledger_transactions_type_check";
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transactions_type_check" CHECK ("type" IN ('deposit', 'withdrawal'));
+
+ALTER TABLE "ledger_transactions" ALTER COLUMN "amount" SET NOT NULL;
+
+ALTER TABLE "ledger_transactions" ALTER COLUMN "account_id" SET NOT NULL;
+
+COMMENT ON COLUMN "ledger_transactions"."type" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "ledger_transactions"."account_id" IS 'customer account funded or drawn down';
+
+COMMENT ON COLUMN "ledger_transactions"."amount" IS 'must be positive';
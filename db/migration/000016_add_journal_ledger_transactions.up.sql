@@ -0,0 +1,16 @@
+ALTER TABLE "ledger_transactions" ALTER COLUMN "account_id" DROP NOT NULL;
+
+ALTER TABLE "ledger_transactions" ALTER COLUMN "amount" DROP NOT NULL;
+
+ALTER TABLE "ledger_transactions" DROP CONSTRAINT "ledger_transactions_type_check";
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transactions_type_check" CHECK ("type" IN ('deposit', 'withdrawal', 'journal'));
+
+-- 多分录记账的账户和金额都在分录上，存取款仍然必须填
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transactions_account_check" CHECK ("type" = 'journal' OR ("account_id" IS NOT NULL AND "amount" IS NOT NULL));
+
+COMMENT ON COLUMN "ledger_transactions"."type" IS 'deposit, withdrawal or journal';
+
+COMMENT ON COLUMN "ledger_transactions"."account_id" IS 'customer account funded or drawn down, null for journals';
+
+COMMENT ON COLUMN "ledger_transactions"."amount" IS 'must be positive, null for journals';
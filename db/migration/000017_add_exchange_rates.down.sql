@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS "transfer_quotes";
+
+DROP TABLE IF EXISTS "exchange_rates";
+
+UPDATE "entries" SET "ledger_transaction_id" = NULL
+WHERE "ledger_transaction_id" IN (SELECT "id" FROM "ledger_transactions" WHERE "type" = 'fx_transfer');
+
+DELETE FROM "ledger_transactions" WHERE "type" = 'fx_transfer';
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'fx');
+
+DELETE FROM "accounts" WHERE "owner" = 'fx';
+
+DELETE FROM "users" WHERE "username" = 'fx';
+
+ALTER TABLE IF EXISTS "ledger_transactions" DROP CONSTRAINT IF EXISTS "ledger_transactions_type_check";
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transactions_type_check" CHECK ("type" IN ('deposit', 'withdrawal', 'journal'));
+
+COMMENT ON COLUMN "ledger_transactions"."type" IS 'deposit, withdrawal or journal';
+
+COMMENT ON COLUMN "ledger_transactions"."account_id" IS 'customer account funded or drawn down, null for journals';
+
+COMMENT ON COLUMN "ledger_transactions"."amount" IS 'must be positive, null for journals';
@@ -0,0 +1,68 @@
+CREATE TABLE "exchange_rates" (
+  "id" bigserial PRIMARY KEY,
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" numeric(24,12) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "exchange_rates_rate_positive" CHECK ("rate" > 0);
+
+CREATE INDEX ON "exchange_rates" ("base_currency", "quote_currency", "created_at");
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'amount of quote currency for one unit of base currency';
+
+CREATE TABLE "transfer_quotes" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "converted_amount" bigint NOT NULL,
+  "exchange_rate_id" bigint NOT NULL,
+  "rate" numeric(24,12) NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "ledger_transaction_id" bigint,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_quotes" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_quotes" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_quotes" ADD FOREIGN KEY ("exchange_rate_id") REFERENCES "exchange_rates" ("id");
+
+ALTER TABLE "transfer_quotes" ADD FOREIGN KEY ("ledger_transaction_id") REFERENCES "ledger_transactions" ("id");
+
+ALTER TABLE "transfer_quotes" ADD CONSTRAINT "transfer_quotes_amount_positive" CHECK ("amount" > 0 AND "converted_amount" > 0);
+
+COMMENT ON COLUMN "transfer_quotes"."amount" IS 'amount debited from the source account, in its currency';
+
+COMMENT ON COLUMN "transfer_quotes"."converted_amount" IS 'amount credited to the destination account, in its currency';
+
+COMMENT ON COLUMN "transfer_quotes"."ledger_transaction_id" IS 'transfer that used the quote';
+
+ALTER TABLE "ledger_transactions" DROP CONSTRAINT "ledger_transactions_type_check";
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transactions_type_check" CHECK ("type" IN ('deposit', 'withdrawal', 'journal', 'fx_transfer'));
+
+COMMENT ON COLUMN "ledger_transactions"."type" IS 'deposit, withdrawal, journal or fx_transfer';
+
+COMMENT ON COLUMN "ledger_transactions"."account_id" IS 'customer account funded or drawn down, source account of fx transfers, null for journals';
+
+COMMENT ON COLUMN "ledger_transactions"."amount" IS 'must be positive, in the currency of account_id, null for journals';
+
+-- 客户已经注册了同名用户时中止迁移，不能把它当成银行自己的用户
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM "users" WHERE "username" = 'fx') THEN
+    RAISE EXCEPTION 'username fx is reserved for the bank but is already registered, rename that user before migrating';
+  END IF;
+END $$;
+
+-- 换汇账户挂在fx用户下，每种货币一个，和现金账户分开，方便核对换汇的头寸
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "disabled_at")
+VALUES ('fx', '', 'SimpleBank FX', 'fx@simplebank.invalid', now());
@@ -0,0 +1,24 @@
+CREATE TABLE "currencies" (
+  "code" varchar PRIMARY KEY,
+  "minor_units" int NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true
+);
+
+ALTER TABLE "currencies" ADD CONSTRAINT "currencies_code_check" CHECK ("code" ~ '^[A-Z]{3}$');
+
+ALTER TABLE "currencies" ADD CONSTRAINT "currencies_minor_units_check" CHECK ("minor_units" BETWEEN 0 AND 4);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 code';
+
+COMMENT ON COLUMN "currencies"."minor_units" IS 'decimals of the major unit; amounts are stored in the minor unit';
+
+COMMENT ON COLUMN "currencies"."enabled" IS 'disabled currencies keep their accounts but can''t be used for new ones';
+
+INSERT INTO "currencies" ("code", "minor_units") VALUES
+  ('USD', 2),
+  ('EUR', 2),
+  ('CAD', 2),
+  ('JPY', 0),
+  ('GBP', 2);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
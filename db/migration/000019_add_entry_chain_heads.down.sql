@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "entry_chain_heads";
+
+DROP TABLE IF EXISTS "entry_chain_cutoffs";
+
+COMMENT ON COLUMN "entries"."hash" IS 'sha256 over the entry and prev_hash, null for entries written before the chain existed';
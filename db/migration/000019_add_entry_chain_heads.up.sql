@@ -0,0 +1,37 @@
+CREATE TABLE "entry_chain_cutoffs" (
+  "singleton" boolean PRIMARY KEY DEFAULT true,
+  "hashed_from_entry_id" bigint NOT NULL,
+  "keyed_from_entry_id" bigint NOT NULL
+);
+
+ALTER TABLE "entry_chain_cutoffs" ADD CONSTRAINT "entry_chain_cutoffs_singleton_check" CHECK ("singleton");
+
+COMMENT ON COLUMN "entry_chain_cutoffs"."hashed_from_entry_id" IS 'entries from this id on must have a hash';
+
+COMMENT ON COLUMN "entry_chain_cutoffs"."keyed_from_entry_id" IS 'entries from this id on are hashed with LEDGER_HASH_KEY, earlier ones with plain sha256';
+
+-- 000014之后写入的分录都有哈希；从这里开始的新分录都用密钥计算哈希
+INSERT INTO "entry_chain_cutoffs" ("hashed_from_entry_id", "keyed_from_entry_id")
+SELECT
+  COALESCE(MIN("id") FILTER (WHERE "hash" IS NOT NULL), COALESCE(MAX("id"), 0) + 1),
+  COALESCE(MAX("id"), 0) + 1
+FROM "entries";
+
+CREATE TABLE "entry_chain_heads" (
+  "account_id" bigint PRIMARY KEY,
+  "head_entry_id" bigint NOT NULL,
+  "head_hash" bytea NOT NULL,
+  "entry_count" bigint NOT NULL,
+  "mac" bytea NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entry_chain_heads" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+COMMENT ON COLUMN "entry_chain_heads"."head_entry_id" IS 'last entry of the chain of the account';
+
+COMMENT ON COLUMN "entry_chain_heads"."entry_count" IS 'number of hashed entries of the account';
+
+COMMENT ON COLUMN "entry_chain_heads"."mac" IS 'hmac over the other columns with LEDGER_HASH_KEY';
+
+COMMENT ON COLUMN "entries"."hash" IS 'hmac-sha256 with LEDGER_HASH_KEY over the entry and prev_hash, plain sha256 before keyed_from_entry_id, null for entries written before the chain existed';
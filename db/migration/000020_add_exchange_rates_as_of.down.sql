@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "exchange_rates_base_currency_quote_currency_as_of_idx";
+
+ALTER TABLE IF EXISTS "exchange_rates" DROP COLUMN IF EXISTS "as_of";
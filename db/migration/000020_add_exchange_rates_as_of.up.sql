@@ -0,0 +1,10 @@
+ALTER TABLE "exchange_rates" ADD COLUMN "as_of" timestamptz;
+
+-- 之前的汇率只知道写入时间
+UPDATE "exchange_rates" SET "as_of" = "created_at";
+
+ALTER TABLE "exchange_rates" ALTER COLUMN "as_of" SET NOT NULL;
+
+CREATE INDEX ON "exchange_rates" ("base_currency", "quote_currency", "as_of");
+
+COMMENT ON COLUMN "exchange_rates"."as_of" IS 'when the source published the rate; freshness is checked against it, not created_at';
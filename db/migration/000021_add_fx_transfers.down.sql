@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "quote_id";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "converted_amount";
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
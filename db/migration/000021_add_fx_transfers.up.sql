@@ -0,0 +1,15 @@
+ALTER TABLE "transfers" ADD COLUMN "converted_amount" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "quote_id" uuid;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("quote_id") REFERENCES "transfer_quotes" ("id");
+
+CREATE UNIQUE INDEX ON "transfers" ("quote_id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_fx_check" CHECK (("converted_amount" IS NULL) = ("quote_id" IS NULL) AND "converted_amount" > 0);
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the currency of the from account';
+
+COMMENT ON COLUMN "transfers"."converted_amount" IS 'amount credited to the to account in its currency, null when both accounts hold the same currency';
+
+COMMENT ON COLUMN "transfers"."quote_id" IS 'quote that locked the rate of a transfer in another currency';
@@ -31,4 +31,34 @@ FOR NO KEY UPDATE;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id=$1;
\ No newline at end of file
+WHERE id=$1;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = sqlc.arg(overdraft_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListAccountsAfter :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+
+-- name: ListAccountIDs :many
+SELECT id FROM accounts
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CreateAccountIfNotExists :exec
+INSERT INTO accounts (owner, balance, currency)
+VALUES (sqlc.arg(owner), 0, sqlc.arg(currency))
+ON CONFLICT (owner, currency) DO NOTHING;
+
+-- name: GetOwnerAccount :one
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner) AND currency = sqlc.arg(currency);
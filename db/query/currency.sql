@@ -0,0 +1,3 @@
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
@@ -1,6 +1,6 @@
 -- name: CreateEntry :one
-INSERT INTO entries (account_id,amount)
-VALUES($1,$2) RETURNING *;
+INSERT INTO entries (account_id,amount,transfer_id)
+VALUES($1,$2,$3) RETURNING *;
 
 -- name: ListEntries :many
 SELECT * FROM entries
@@ -11,4 +11,63 @@ OFFSET $3;
 
 -- name: GetEntry :one
 SELECT * FROM entries
-WHERE id=$1;
\ No newline at end of file
+WHERE id=$1;
+
+-- name: ListStatementEntries :many
+SELECT
+  e.id,
+  e.account_id,
+  e.amount,
+  e.transfer_id,
+  e.created_at,
+  COALESCE(CASE
+    WHEN t.from_account_id = e.account_id THEN t.to_account_id
+    ELSE t.from_account_id
+  END, 0)::bigint AS counterparty_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(start_time)
+  AND e.created_at < sqlc.arg(end_time)
+ORDER BY e.created_at, e.id;
+
+-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(since);
+
+-- name: ListEntriesAfter :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+
+-- name: CreateChainedEntry :one
+INSERT INTO entries (
+  account_id,
+  amount,
+  transfer_id,
+  ledger_transaction_id,
+  created_at,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetLastEntryHash :one
+-- the caller must hold the lock on the account so the chain can't fork
+SELECT hash FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListEntryChain :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id) AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,28 @@
+-- name: CountChainedEntries :one
+SELECT COUNT(*) FROM entries
+WHERE account_id = $1 AND hash IS NOT NULL;
+
+-- name: GetEntryChainCutoff :one
+SELECT * FROM entry_chain_cutoffs;
+
+-- name: GetEntryChainHead :one
+SELECT * FROM entry_chain_heads
+WHERE account_id = $1;
+
+-- name: UpsertEntryChainHead :exec
+-- the caller must hold the lock on the account
+INSERT INTO entry_chain_heads (
+  account_id,
+  head_entry_id,
+  head_hash,
+  entry_count,
+  mac
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id) DO UPDATE
+SET head_entry_id = EXCLUDED.head_entry_id,
+    head_hash = EXCLUDED.head_hash,
+    entry_count = EXCLUDED.entry_count,
+    mac = EXCLUDED.mac,
+    updated_at = now();
@@ -0,0 +1,16 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  username,
+  idempotency_key,
+  request_hash,
+  response
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND idempotency_key = $2 LIMIT 1;
+
+-- name: LockIdempotencyKey :exec
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg(username)::text || ':' || sqlc.arg(key)::text, 0));
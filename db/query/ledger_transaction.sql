@@ -0,0 +1,12 @@
+-- name: CreateLedgerTransaction :one
+INSERT INTO ledger_transactions (
+  type,
+  account_id,
+  amount
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetLedgerTransaction :one
+SELECT * FROM ledger_transactions
+WHERE id = $1;
@@ -0,0 +1,42 @@
+-- name: ListBalanceMismatches :many
+SELECT a.id AS account_id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListUnmatchedTransfers :many
+-- a transfer must have exactly one debit entry on the from account for its
+-- amount and one credit entry on the to account for its converted amount,
+-- which is the same amount when both accounts hold the same currency
+SELECT t.id AS transfer_id, t.from_account_id, t.to_account_id, t.amount, COUNT(e.id) AS entry_count
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> 2
+  OR COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) <> 1
+  OR COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = COALESCE(t.converted_amount, t.amount)) <> 1
+ORDER BY t.id;
+
+-- name: ListUnbalancedLedgerTransactions :many
+-- the entries of a ledger transaction must sum to zero in every currency; a
+-- deposit or withdrawal must also have exactly one entry on the customer
+-- account for the signed amount, balanced by one on the system account
+SELECT l.id AS ledger_transaction_id, l.type, l.account_id, l.amount,
+  COUNT(e.id) AS entry_count, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM ledger_transactions l
+LEFT JOIN entries e ON e.ledger_transaction_id = l.id
+GROUP BY l.id
+HAVING COUNT(e.id) < 2
+  OR COALESCE(SUM(e.amount), 0) <> 0
+  OR EXISTS (
+    SELECT 1 FROM entries ce
+    JOIN accounts a ON a.id = ce.account_id
+    WHERE ce.ledger_transaction_id = l.id
+    GROUP BY a.currency
+    HAVING SUM(ce.amount) <> 0)
+  OR (l.type IN ('deposit', 'withdrawal') AND (COUNT(e.id) <> 2
+    OR COUNT(e.id) FILTER (WHERE e.account_id = l.account_id
+      AND e.amount = CASE WHEN l.type = 'deposit' THEN l.amount ELSE -l.amount END) <> 1))
+ORDER BY l.id;
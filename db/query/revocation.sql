@@ -0,0 +1,40 @@
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (
+  id,
+  username,
+  expires_at
+) VALUES (
+  $1, $2, $3
+) ON CONFLICT (id) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (
+  SELECT 1 FROM revoked_tokens WHERE id = $1
+) AS revoked;
+
+-- name: RevokeSessionFamilyTokens :exec
+-- the id of a session is the jti of its refresh token, so it is revoked along
+-- with the access token
+INSERT INTO revoked_tokens (id, username, expires_at)
+SELECT access_token_id, username, expires_at FROM sessions
+WHERE family_id = $1 AND access_token_id IS NOT NULL
+UNION ALL
+SELECT id, username, expires_at FROM sessions
+WHERE family_id = $1
+ON CONFLICT (id) DO NOTHING;
+
+-- name: SetTokenNotBefore :exec
+INSERT INTO token_not_before (
+  username,
+  not_before
+) VALUES (
+  $1, $2
+) ON CONFLICT (username) DO UPDATE
+SET not_before = GREATEST(token_not_before.not_before, EXCLUDED.not_before);
+
+-- name: GetTokenNotBefore :one
+-- 修改密码之前签发的token同样失效
+SELECT GREATEST(u.password_changed_at, t.not_before)::timestamptz AS not_before
+FROM users u
+LEFT JOIN token_not_before t ON t.username = u.username
+WHERE u.username = $1;
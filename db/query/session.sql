@@ -6,11 +6,53 @@ INSERT INTO sessions (
   user_agent,
   client_ip,
   is_blocked,
-  expires_at
+  expires_at,
+  family_id,
+  access_token_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: ConsumeSession :one
+UPDATE sessions
+SET consumed_at = now()
+WHERE id = $1 AND consumed_at IS NULL
+RETURNING *;
+
+-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1;
+
+-- name: ListSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND consumed_at IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = (
+  SELECT family_id FROM sessions
+  WHERE id = sqlc.arg(id) AND username = sqlc.arg(username)
+);
+
+-- name: RevokeAllSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND is_blocked = false;
+
+
+-- name: CountActiveSessions :one
+SELECT count(*) FROM sessions
+WHERE username = $1
+  AND is_blocked = false
+  AND consumed_at IS NULL
+  AND expires_at > now();
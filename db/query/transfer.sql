@@ -2,6 +2,12 @@
 INSERT INTO transfers(from_account_id,to_account_id,amount)
 VALUES($1,$2,$3)RETURNING *;
 
+-- name: CreateFXTransfer :one
+-- amount is debited in the currency of the from account, converted_amount
+-- credited in the currency of the to account at the rate of the quote
+INSERT INTO transfers(from_account_id,to_account_id,amount,converted_amount,quote_id)
+VALUES($1,$2,$3,$4,$5)RETURNING *;
+
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id=$1;
@@ -12,3 +18,28 @@ WHERE from_account_id=$1 OR to_account_id=$2
 ORDER BY id
 LIMIT $3
 OFFSET $4;
+
+-- name: FilterTransfers :many
+WITH owned_accounts AS (
+  SELECT id FROM accounts
+  WHERE owner = sqlc.arg(owner)
+    AND (sqlc.narg(account_id)::bigint IS NULL OR id = sqlc.narg(account_id))
+)
+SELECT t.* FROM transfers t
+WHERE (
+    (sqlc.arg(include_outgoing)::boolean
+      AND t.from_account_id IN (SELECT id FROM owned_accounts)
+      AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_account_id)))
+    OR (sqlc.arg(include_incoming)::boolean
+      AND t.to_account_id IN (SELECT id FROM owned_accounts)
+      AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_account_id)))
+  )
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (t.created_at, t.id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint))
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
@@ -0,0 +1,25 @@
+-- name: CreateTransferQuote :one
+INSERT INTO transfer_quotes (
+  id,
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  converted_amount,
+  exchange_rate_id,
+  rate,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetTransferQuoteForUpdate :one
+SELECT * FROM transfer_quotes
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UseTransferQuote :one
+UPDATE transfer_quotes
+SET used_at = now(), ledger_transaction_id = sqlc.arg(ledger_transaction_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
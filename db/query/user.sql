@@ -14,4 +14,29 @@ SET
     email = COALESCE(sqlc.narg('email'),email)
 WHERE 
 username=sqlc.arg(username)
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ListUsers :many
+-- search matches part of the username or email, case-insensitively; its
+-- \, % and _ are escaped so they match themselves
+SELECT * FROM users
+WHERE (sqlc.narg(search)::text IS NULL
+    OR username ILIKE '%' || replace(replace(replace(sqlc.narg(search)::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR email ILIKE '%' || replace(replace(replace(sqlc.narg(search)::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (created_at, username) > (sqlc.narg(after_created_at)::timestamptz, sqlc.arg(after_username)::text))
+ORDER BY created_at, username
+LIMIT sqlc.arg('limit');
+
+-- name: DisableUser :one
+-- a user that is already disabled keeps its original disabled_at
+UPDATE users
+SET disabled_at = COALESCE(disabled_at, sqlc.arg(disabled_at))
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: EnableUser :one
+UPDATE users
+SET disabled_at = NULL
+WHERE username = $1
+RETURNING *;
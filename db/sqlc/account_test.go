@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -44,6 +45,20 @@ func createRandomAccount(t *testing.T) (Account, error) {
 	return account, err
 }
 
+// createFundedAccount creates an account of a new user with the given
+// currency and balance
+func createFundedAccount(t *testing.T, currency string, balance int64) Account {
+	user, err := createRandomUser(t)
+	require.NoError(t, err)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
 func CreateAccountWithFixedOwner(t *testing.T, owner string) Account {
 	arg := CreateAccountParams{
 		Owner:    owner,
@@ -127,6 +142,36 @@ func TestListAccounts(t *testing.T) {
 	}
 }
 
+func TestListAccountsAfter(t *testing.T) {
+	user, err := createRandomUser(t)
+	require.NoError(t, err)
+
+	var accounts []Account
+	for _, currency := range []string{"USD", "EUR", "CAD", "JPY", "GBP"} {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		accounts = append(accounts, account)
+	}
+
+	arg := ListAccountsAfterParams{
+		Owner: user.Username,
+		Limit: 3,
+	}
+	page1, err := testQueries.ListAccountsAfter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, accounts[:3], page1)
+
+	last := page1[len(page1)-1]
+	arg.AfterCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+	arg.AfterID = last.ID
+	page2, err := testQueries.ListAccountsAfter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, accounts[3:], page2)
+}
+
 func TestUpdateAccountBalance(t *testing.T) {
 	account, _ := createRandomAccount(t)
 	arg := UpdateAccountBalanceParams{
@@ -147,3 +192,17 @@ func TestAddAccountBalance(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, account.Balance+arg.Amount, updateAccount.Balance)
 }
+
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
+	account, _ := createRandomAccount(t)
+	require.Zero(t, account.OverdraftLimit)
+
+	arg := UpdateAccountOverdraftLimitParams{
+		ID:             account.ID,
+		OverdraftLimit: utils.RandomInt63(1, 5000),
+	}
+	updatedAccount, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.OverdraftLimit, updatedAccount.OverdraftLimit)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
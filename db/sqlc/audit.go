@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditUserCreate      = "user.create"
+	AuditUserUpdate      = "user.update"
+	AuditUserDisable     = "user.disable"
+	AuditUserEnable      = "user.enable"
+	AuditSessionsRevoke  = "user.revoke_sessions"
+	AuditSessionCreate   = "session.create"
+	AuditSessionRotate   = "session.rotate"
+	AuditSessionRevoke   = "session.revoke"
+	AuditSessionReuse    = "session.reuse_blocked"
+	AuditAccountCreate   = "account.create"
+	AuditAccountDeposit  = "account.deposit"
+	AuditAccountWithdraw = "account.withdraw"
+	AuditTransferCreate  = "transfer.create"
+	AuditFXTransfer      = "transfer.fx"
+	AuditJournalPost     = "journal.post"
+	AuditQuoteCreate     = "transfer_quote.create"
+)
+
+// Types of the targets of audit events
+const (
+	AuditTargetUser              = "user"
+	AuditTargetAccount           = "account"
+	AuditTargetTransfer          = "transfer"
+	AuditTargetLedgerTransaction = "ledger_transaction"
+	AuditTargetSession           = "session"
+	AuditTargetTransferQuote     = "transfer_quote"
+)
+
+// AuditParams identifies who made a change and where the request came from.
+// Transactions given one append an audit event describing the change.
+type AuditParams struct {
+	Actor     string
+	ClientIP  string
+	UserAgent string
+}
+
+// auditedUser is the part of a user recorded in the audit log, leaving out
+// the password hash
+type auditedUser struct {
+	Username          string       `json:"username"`
+	FullName          string       `json:"full_name"`
+	Email             string       `json:"email"`
+	Role              string       `json:"role"`
+	PasswordChangedAt time.Time    `json:"password_changed_at"`
+	DisabledAt        sql.NullTime `json:"disabled_at"`
+}
+
+func auditUser(user User) auditedUser {
+	return auditedUser{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		PasswordChangedAt: user.PasswordChangedAt,
+		DisabledAt:        user.DisabledAt,
+	}
+}
+
+// auditedSession is the part of a session recorded in the audit log, leaving
+// out the refresh token
+type auditedSession struct {
+	ID            uuid.UUID     `json:"id"`
+	Username      string        `json:"username"`
+	FamilyID      uuid.UUID     `json:"family_id"`
+	UserAgent     string        `json:"user_agent"`
+	ClientIp      string        `json:"client_ip"`
+	IsBlocked     bool          `json:"is_blocked"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	ConsumedAt    sql.NullTime  `json:"consumed_at"`
+	AccessTokenID uuid.NullUUID `json:"access_token_id"`
+}
+
+func auditSession(session Session) auditedSession {
+	return auditedSession{
+		ID:            session.ID,
+		Username:      session.Username,
+		FamilyID:      session.FamilyID,
+		UserAgent:     session.UserAgent,
+		ClientIp:      session.ClientIp,
+		IsBlocked:     session.IsBlocked,
+		ExpiresAt:     session.ExpiresAt,
+		ConsumedAt:    session.ConsumedAt,
+		AccessTokenID: session.AccessTokenID,
+	}
+}
+
+// recordAudit appends an audit event in the transaction of the change it
+// describes, so that the change and its record commit or roll back together.
+// A nil before or after is stored as JSON null.
+func recordAudit(ctx context.Context, q *Queries, audit *AuditParams, action string, targetType string, targetID string, before any, after any) error {
+	if audit == nil {
+		return nil
+	}
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		Actor:      audit.Actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		ClientIp:   audit.ClientIP,
+		UserAgent:  audit.UserAgent,
+	})
+	return err
+}
+
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
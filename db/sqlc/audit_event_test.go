@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func randomAudit(actor string) *AuditParams {
+	return &AuditParams{
+		Actor:     actor,
+		ClientIP:  "127.0.0.1",
+		UserAgent: utils.RandomString(8),
+	}
+}
+
+func listTargetEvents(t *testing.T, targetType string, targetID string) []AuditEvent {
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		TargetType: sql.NullString{String: targetType, Valid: true},
+		TargetID:   sql.NullString{String: targetID, Valid: true},
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestCreateUserTxAudit(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	hashedPassword, err := utils.RandomHashPassWord()
+	require.NoError(t, err)
+
+	username := utils.RandomOwnerName()
+	audit := randomAudit(username)
+	user, err := store.CreateUserTx(context.Background(), CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       username,
+			HashedPassword: hashedPassword,
+			FullName:       utils.RandomString(5),
+			Email:          utils.RandomEmail(),
+		},
+		Audit: audit,
+	})
+	require.NoError(t, err)
+
+	events := listTargetEvents(t, AuditTargetUser, user.Username)
+	require.Len(t, events, 1)
+	require.Equal(t, audit.Actor, events[0].Actor)
+	require.Equal(t, AuditUserCreate, events[0].Action)
+	require.Equal(t, audit.ClientIP, events[0].ClientIp)
+	require.Equal(t, audit.UserAgent, events[0].UserAgent)
+	require.JSONEq(t, "null", string(events[0].Before))
+
+	// 审计日志里不能出现密码哈希
+	var after map[string]any
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, user.Username, after["username"])
+	require.NotContains(t, after, "hashed_password")
+}
+
+func TestUpdateUserTxAudit(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	user, _ := createRandomUser(t)
+
+	newFullName := utils.RandomOwnerName()
+	_, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: user.Username,
+			FullName: sql.NullString{String: newFullName, Valid: true},
+		},
+		Audit: randomAudit(user.Username),
+	})
+	require.NoError(t, err)
+
+	events := listTargetEvents(t, AuditTargetUser, user.Username)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditUserUpdate, events[0].Action)
+
+	var before, after map[string]any
+	require.NoError(t, json.Unmarshal(events[0].Before, &before))
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, user.FullName, before["full_name"])
+	require.Equal(t, newFullName, after["full_name"])
+}
+
+func TestTransferTxRollsBackAudit(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account1, _ := createRandomAccount(t)
+	account2, _ := createRandomAccount(t)
+
+	// 转账失败时审计记录随事务一起回滚
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + account1.OverdraftLimit + 1,
+		Audit:         randomAudit(account1.Owner),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor: sql.NullString{String: account1.Owner, Valid: true},
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Audit:         randomAudit(account1.Owner),
+	})
+	require.NoError(t, err)
+
+	events = listTargetEvents(t, AuditTargetTransfer, auditID(result.Transfer.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, AuditTransferCreate, events[0].Action)
+}
+
+func TestCreateSessionTxAudit(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	user, _ := createRandomUser(t)
+
+	session, err := store.CreateSessionTx(context.Background(), CreateSessionTxParams{
+		CreateSessionParams: randomSessionParams(user.Username, uuid.Nil),
+		Audit:               randomAudit(user.Username),
+	})
+	require.NoError(t, err)
+
+	events := listTargetEvents(t, AuditTargetSession, session.ID.String())
+	require.Len(t, events, 1)
+	require.Equal(t, AuditSessionCreate, events[0].Action)
+
+	// 审计日志里不能出现刷新token
+	var after map[string]any
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, session.FamilyID.String(), after["family_id"])
+	require.NotContains(t, after, "refresh_token")
+}
+
+func TestRotateSessionTxAudit(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+
+	newSession, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  session.ID,
+		NewSession: randomSessionParams(session.Username, uuid.Nil),
+		Audit:      randomAudit(session.Username),
+	})
+	require.NoError(t, err)
+
+	events := listTargetEvents(t, AuditTargetSession, newSession.ID.String())
+	require.Len(t, events, 1)
+	require.Equal(t, AuditSessionRotate, events[0].Action)
+
+	// 重复使用刷新token时，封禁会话族的记录随封禁一起提交
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  session.ID,
+		NewSession: randomSessionParams(session.Username, uuid.Nil),
+		Audit:      randomAudit(session.Username),
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	events = listTargetEvents(t, AuditTargetSession, session.ID.String())
+	require.Len(t, events, 1)
+	require.Equal(t, AuditSessionReuse, events[0].Action)
+}
+
+func TestRevokeSessionTxAudit(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+
+	revoked, err := store.RevokeSessionTx(context.Background(), RevokeSessionTxParams{
+		RevokeSessionParams: RevokeSessionParams{
+			ID:       session.ID,
+			Username: session.Username,
+		},
+		Audit: randomAudit(session.Username),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	events := listTargetEvents(t, AuditTargetSession, session.ID.String())
+	require.Len(t, events, 1)
+	require.Equal(t, AuditSessionRevoke, events[0].Action)
+}
+
+func TestCreateTransferQuoteTxAudit(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	from := createFundedAccount(t, "USD", 1000)
+	to := createFundedAccount(t, "EUR", 0)
+	rate, err := testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+		BaseCurrency:  from.Currency,
+		QuoteCurrency: to.Currency,
+		Rate:          "0.9",
+		AsOf:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	quote, err := store.CreateTransferQuoteTx(context.Background(), CreateTransferQuoteTxParams{
+		CreateTransferQuoteParams: CreateTransferQuoteParams{
+			ID:              uuid.New(),
+			Username:        from.Owner,
+			FromAccountID:   from.ID,
+			ToAccountID:     to.ID,
+			Amount:          100,
+			ConvertedAmount: 90,
+			ExchangeRateID:  rate.ID,
+			Rate:            rate.Rate,
+			ExpiresAt:       time.Now().Add(time.Minute),
+		},
+		Audit: randomAudit(from.Owner),
+	})
+	require.NoError(t, err)
+
+	events := listTargetEvents(t, AuditTargetTransferQuote, quote.ID.String())
+	require.Len(t, events, 1)
+	require.Equal(t, AuditQuoteCreate, events[0].Action)
+}
+
+func TestListAuditEventsKeyset(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	user, _ := createRandomUser(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+			UpdateUserParams: UpdateUserParams{
+				Username: user.Username,
+				FullName: sql.NullString{String: utils.RandomOwnerName(), Valid: true},
+			},
+			Audit: randomAudit(user.Username),
+		})
+		require.NoError(t, err)
+	}
+
+	arg := ListAuditEventsParams{
+		Actor: sql.NullString{String: user.Username, Valid: true},
+		Limit: 2,
+	}
+	page1, err := testQueries.ListAuditEvents(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page1, 2)
+	require.True(t, page1[0].ID > page1[1].ID)
+
+	last := page1[len(page1)-1]
+	arg.BeforeCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+	arg.BeforeID = last.ID
+	page2, err := testQueries.ListAuditEvents(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page2, 1)
+	require.True(t, page2[0].ID < last.ID)
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	user, _ := createRandomUser(t)
+	_, err := store.EnableUserTx(context.Background(), EnableUserTxParams{
+		Username: user.Username,
+		Audit:    randomAudit(user.Username),
+	})
+	require.NoError(t, err)
+
+	_, err = testDB.Exec("UPDATE audit_events SET actor = 'someone' WHERE actor = $1", user.Username)
+	require.Error(t, err)
+	_, err = testDB.Exec("DELETE FROM audit_events WHERE actor = $1", user.Username)
+	require.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package db
+
+import "context"
+
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	Idempotency *IdempotencyParams `json:"-"`
+	Audit       *AuditParams       `json:"-"`
+}
+
+// CreateAccountTx creates an account, honouring the idempotency key if one is given
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
+	err := store.idempotentTx(ctx, arg.Idempotency, "create_account", arg.CreateAccountParams, &account, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditAccountCreate, AuditTargetAccount, auditID(account.ID), nil, account)
+	})
+	return account, err
+}
@@ -0,0 +1,22 @@
+package db
+
+import "context"
+
+type CreateSessionTxParams struct {
+	CreateSessionParams
+	Audit *AuditParams `json:"-"`
+}
+
+// CreateSessionTx creates the session of a login
+func (store *SQLStore) CreateSessionTx(ctx context.Context, arg CreateSessionTxParams) (Session, error) {
+	var session Session
+	err := store.exeTx(ctx, func(q *Queries) error {
+		var err error
+		session, err = q.CreateSession(ctx, arg.CreateSessionParams)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditSessionCreate, AuditTargetSession, session.ID.String(), nil, auditSession(session))
+	})
+	return session, err
+}
@@ -0,0 +1,22 @@
+package db
+
+import "context"
+
+type CreateUserTxParams struct {
+	CreateUserParams
+	Audit *AuditParams `json:"-"`
+}
+
+// CreateUserTx creates a user and records it in the audit log
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (User, error) {
+	var user User
+	err := store.exeTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditUserCreate, AuditTargetUser, user.Username, nil, auditUser(user))
+	})
+	return user, err
+}
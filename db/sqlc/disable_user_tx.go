@@ -0,0 +1,59 @@
+package db
+
+import "context"
+
+type DisableUserTxParams struct {
+	DisableUserParams
+	Audit *AuditParams `json:"-"`
+}
+
+// DisableUserTx disables the user and signs it out everywhere: all sessions
+// are blocked and every token issued before DisabledAt is revoked.
+func (store *SQLStore) DisableUserTx(ctx context.Context, arg DisableUserTxParams) (User, error) {
+	var user User
+	err := store.exeTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.DisableUser(ctx, arg.DisableUserParams)
+		if err != nil {
+			return err
+		}
+		_, err = q.RevokeAllSessions(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		err = q.SetTokenNotBefore(ctx, SetTokenNotBeforeParams{
+			Username:  arg.Username,
+			NotBefore: arg.DisabledAt.Time,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditUserDisable, AuditTargetUser, user.Username, auditUser(before), auditUser(user))
+	})
+	return user, err
+}
+
+type EnableUserTxParams struct {
+	Username string       `json:"username"`
+	Audit    *AuditParams `json:"-"`
+}
+
+// EnableUserTx lets a disabled user log in again
+func (store *SQLStore) EnableUserTx(ctx context.Context, arg EnableUserTxParams) (User, error) {
+	var user User
+	err := store.exeTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.EnableUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditUserEnable, AuditTargetUser, user.Username, auditUser(before), auditUser(user))
+	})
+	return user, err
+}
@@ -7,32 +7,87 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const createChainedEntry = `-- name: CreateChainedEntry :one
+INSERT INTO entries (
+  account_id,
+  amount,
+  transfer_id,
+  ledger_transaction_id,
+  created_at,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, account_id, amount, created_at, transfer_id, prev_hash, hash, ledger_transaction_id
+`
+
+type CreateChainedEntryParams struct {
+	AccountID           int64         `json:"account_id"`
+	Amount              int64         `json:"amount"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	LedgerTransactionID sql.NullInt64 `json:"ledger_transaction_id"`
+	CreatedAt           time.Time     `json:"created_at"`
+	PrevHash            []byte        `json:"prev_hash"`
+	Hash                []byte        `json:"hash"`
+}
+
+func (q *Queries) CreateChainedEntry(ctx context.Context, arg CreateChainedEntryParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, createChainedEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.LedgerTransactionID,
+		arg.CreatedAt,
+		arg.PrevHash,
+		arg.Hash,
+	)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.LedgerTransactionID,
+	)
+	return i, err
+}
+
 const createEntry = `-- name: CreateEntry :one
-INSERT INTO entries (account_id,amount)
-VALUES($1,$2) RETURNING id, account_id, amount, created_at
+INSERT INTO entries (account_id,amount,transfer_id)
+VALUES($1,$2,$3) RETURNING id, account_id, amount, created_at, transfer_id, prev_hash, hash, ledger_transaction_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.LedgerTransactionID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, ledger_transaction_id FROM entries
 WHERE id=$1
 `
 
@@ -44,12 +99,31 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.LedgerTransactionID,
 	)
 	return i, err
 }
 
+const getLastEntryHash = `-- name: GetLastEntryHash :one
+SELECT hash FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT 1
+`
+
+// the caller must hold the lock on the account so the chain can't fork
+func (q *Queries) GetLastEntryHash(ctx context.Context, accountID int64) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, getLastEntryHash, accountID)
+	var hash []byte
+	err := row.Scan(&hash)
+	return hash, err
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, ledger_transaction_id FROM entries
 WHERE account_id=$1
 ORDER BY id
 LIMIT $2
@@ -76,6 +150,172 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.LedgerTransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesAfter = `-- name: ListEntriesAfter :many
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, ledger_transaction_id FROM entries
+WHERE account_id = $1
+  AND ($2::timestamptz IS NULL
+    OR (created_at, id) > ($2::timestamptz, $3::bigint))
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListEntriesAfterParams struct {
+	AccountID      int64        `json:"account_id"`
+	AfterCreatedAt sql.NullTime `json:"after_created_at"`
+	AfterID        int64        `json:"after_id"`
+	Limit          int32        `json:"limit"`
+}
+
+func (q *Queries) ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesAfter,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.LedgerTransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntryChain = `-- name: ListEntryChain :many
+SELECT id, account_id, amount, created_at, transfer_id, prev_hash, hash, ledger_transaction_id FROM entries
+WHERE account_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListEntryChainParams struct {
+	AccountID int64 `json:"account_id"`
+	AfterID   int64 `json:"after_id"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListEntryChain(ctx context.Context, arg ListEntryChainParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntryChain, arg.AccountID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.LedgerTransactionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT
+  e.id,
+  e.account_id,
+  e.amount,
+  e.transfer_id,
+  e.created_at,
+  COALESCE(CASE
+    WHEN t.from_account_id = e.account_id THEN t.to_account_id
+    ELSE t.from_account_id
+  END, 0)::bigint AS counterparty_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                    int64         `json:"id"`
+	AccountID             int64         `json:"account_id"`
+	Amount                int64         `json:"amount"`
+	TransferID            sql.NullInt64 `json:"transfer_id"`
+	CreatedAt             time.Time     `json:"created_at"`
+	CounterpartyAccountID int64         `json:"counterparty_account_id"`
+}
+
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries, arg.AccountID, arg.StartTime, arg.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.CounterpartyAccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -89,3 +329,22 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const sumEntriesSince = `-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+`
+
+type SumEntriesSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesSince, arg.AccountID, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
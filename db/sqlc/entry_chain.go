@@ -0,0 +1,292 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const entryChainBatchSize = 1000
+
+// ChainBreak is the first entry of an account whose link in the hash chain
+// does not hold
+type ChainBreak struct {
+	AccountID int64  `json:"account_id"`
+	EntryID   int64  `json:"entry_id"`
+	Reason    string `json:"reason"`
+}
+
+// MinLedgerHashKeySize is the minimum length of LEDGER_HASH_KEY
+const MinLedgerHashKeySize = 32
+
+// EntryHash returns the hash of an entry in the chain of its account. It covers
+// the content of the entry and the hash of the previous entry, so editing,
+// deleting or reordering entries breaks every link after the change. The hash
+// is an HMAC with key, so that without the key the links after a change can't
+// be recomputed; a nil key gives the plain sha256 of the entries written
+// before the chain was keyed.
+func EntryHash(key []byte, prevHash []byte, accountID int64, amount int64, transferID sql.NullInt64, ledgerTransactionID sql.NullInt64, createdAt time.Time) []byte {
+	h := sha256.New()
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	}
+	h.Write(prevHash)
+	var buf [8]byte
+	for _, v := range []int64{accountID, amount, transferID.Int64, createdAt.UnixMicro()} {
+		binary.BigEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	if transferID.Valid {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+	// 只有存取款的分录带上这一段，之前的分录哈希保持不变
+	if ledgerTransactionID.Valid {
+		binary.BigEndian.PutUint64(buf[:], uint64(ledgerTransactionID.Int64))
+		h.Write(buf[:])
+	}
+	return h.Sum(nil)
+}
+
+// headMAC authenticates the head of the chain of an account, so that the
+// head can't be moved back after entries are removed from the end of the chain
+func headMAC(key []byte, accountID int64, headEntryID int64, entryCount int64, headHash []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	var buf [8]byte
+	for _, v := range []int64{accountID, headEntryID, entryCount} {
+		binary.BigEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	h.Write(headHash)
+	return h.Sum(nil)
+}
+
+// chainTip is the last link of the chain of an account found by walkEntryChain
+type chainTip struct {
+	entryID int64
+	hash    []byte
+	count   int64
+}
+
+// appendEntry appends an entry to the hash chain of its account, filling in
+// CreatedAt, PrevHash and Hash of arg, and moves the head of the chain to the
+// new entry. The account must be locked by the transaction, otherwise two
+// entries could link to the same previous entry.
+func (store *SQLStore) appendEntry(ctx context.Context, q *Queries, arg CreateChainedEntryParams) (Entry, error) {
+	var err error
+	arg.PrevHash, err = q.GetLastEntryHash(ctx, arg.AccountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Entry{}, err
+	}
+
+	var count int64
+	head, err := q.GetEntryChainHead(ctx, arg.AccountID)
+	switch {
+	case err == nil:
+		count = head.EntryCount
+	case errors.Is(err, sql.ErrNoRows):
+		// 还没有锚点的账户从已有的链开始计数
+		count, err = q.CountChainedEntries(ctx, arg.AccountID)
+		if err != nil {
+			return Entry{}, err
+		}
+	default:
+		return Entry{}, err
+	}
+
+	// 数据库只保存到微秒，哈希用的时间要和读回来的一致
+	arg.CreatedAt = time.Now().Truncate(time.Microsecond)
+	arg.Hash = EntryHash(store.ledgerKey, arg.PrevHash, arg.AccountID, arg.Amount, arg.TransferID, arg.LedgerTransactionID, arg.CreatedAt)
+	entry, err := q.CreateChainedEntry(ctx, arg)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	err = store.anchorHead(ctx, q, entry.AccountID, chainTip{entryID: entry.ID, hash: entry.Hash, count: count + 1})
+	return entry, err
+}
+
+func (store *SQLStore) anchorHead(ctx context.Context, q *Queries, accountID int64, tip chainTip) error {
+	return q.UpsertEntryChainHead(ctx, UpsertEntryChainHeadParams{
+		AccountID:   accountID,
+		HeadEntryID: tip.entryID,
+		HeadHash:    tip.hash,
+		EntryCount:  tip.count,
+		Mac:         headMAC(store.ledgerKey, accountID, tip.entryID, tip.count, tip.hash),
+	})
+}
+
+// walkEntryChain checks every link of the chain of an account and returns its
+// last link, or the first link that does not hold. Entries written before the
+// chain existed have no hash and are skipped; from the hashed cutoff on, an
+// entry without a hash is a broken link.
+func (store *SQLStore) walkEntryChain(ctx context.Context, q *Queries, cutoff EntryChainCutoff, accountID int64) (chainTip, *ChainBreak, error) {
+	var tip chainTip
+	var afterID int64
+	for {
+		entries, err := q.ListEntryChain(ctx, ListEntryChainParams{
+			AccountID: accountID,
+			AfterID:   afterID,
+			Limit:     entryChainBatchSize,
+		})
+		if err != nil {
+			return tip, nil, err
+		}
+		for _, entry := range entries {
+			// 密钥生效之前写入的分录仍用不带密钥的sha256
+			var key []byte
+			if entry.ID >= cutoff.KeyedFromEntryID {
+				key = store.ledgerKey
+			}
+			chainBreak := &ChainBreak{AccountID: accountID, EntryID: entry.ID}
+			switch {
+			case entry.Hash == nil && tip.count == 0 && entry.ID < cutoff.HashedFromEntryID:
+				continue
+			case entry.Hash == nil:
+				chainBreak.Reason = "entry has no hash"
+				return tip, chainBreak, nil
+			case !bytes.Equal(entry.PrevHash, tip.hash):
+				chainBreak.Reason = "prev_hash does not match the hash of the previous entry"
+				return tip, chainBreak, nil
+			case !hmac.Equal(entry.Hash, EntryHash(key, entry.PrevHash, entry.AccountID, entry.Amount, entry.TransferID, entry.LedgerTransactionID, entry.CreatedAt)):
+				chainBreak.Reason = "hash does not match the content of the entry"
+				return tip, chainBreak, nil
+			}
+			tip = chainTip{entryID: entry.ID, hash: entry.Hash, count: tip.count + 1}
+		}
+		if len(entries) < entryChainBatchSize {
+			return tip, nil, nil
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+}
+
+// VerifyEntryChain walks the entries of an account in order and returns the
+// first broken link of its hash chain, or nil if the chain is intact. The
+// chain must end at the head anchored for the account, so removing entries
+// from the end of the chain is a broken link too. A head restored from an
+// older copy of entry_chain_heads, along with the entries removed after it,
+// can't be told apart from the chain at that time.
+func (store *SQLStore) VerifyEntryChain(ctx context.Context, accountID int64) (*ChainBreak, error) {
+	var chainBreak *ChainBreak
+	// 在同一个快照里读分录和锚点，并发的转账不会被当成断链
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.exeTxWithOptions(ctx, opts, func(q *Queries) error {
+		cutoff, err := q.GetEntryChainCutoff(ctx)
+		if err != nil {
+			return err
+		}
+		tip, walkBreak, err := store.walkEntryChain(ctx, q, cutoff, accountID)
+		if err != nil || walkBreak != nil {
+			chainBreak = walkBreak
+			return err
+		}
+
+		head, err := q.GetEntryChainHead(ctx, accountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			if tip.count > 0 {
+				chainBreak = &ChainBreak{AccountID: accountID, EntryID: tip.entryID, Reason: "chain has no anchored head"}
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		chainBreak = &ChainBreak{AccountID: accountID, EntryID: head.HeadEntryID}
+		switch {
+		case !hmac.Equal(head.Mac, headMAC(store.ledgerKey, accountID, head.HeadEntryID, head.EntryCount, head.HeadHash)):
+			chainBreak.Reason = "anchored head does not match its mac"
+		case head.HeadEntryID != tip.entryID || head.EntryCount != tip.count || !bytes.Equal(head.HeadHash, tip.hash):
+			chainBreak.Reason = "chain does not end at its anchored head"
+		default:
+			chainBreak = nil
+		}
+		return nil
+	})
+	return chainBreak, err
+}
+
+// AnchorEntryChain verifies the chain of an account that has no anchored head
+// yet, because all its entries were written before heads existed, and anchors
+// its last entry. It returns the first broken link instead if the chain does
+// not hold. Keyed entries always have a head, so a chain of keyed entries
+// without one is reported as broken rather than anchored.
+func (store *SQLStore) AnchorEntryChain(ctx context.Context, accountID int64) (*ChainBreak, error) {
+	var chainBreak *ChainBreak
+	err := store.exeTx(ctx, func(q *Queries) error {
+		// 锁住账户，锚定期间不会有新的分录
+		_, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		_, err = q.GetEntryChainHead(ctx, accountID)
+		if err == nil || !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		cutoff, err := q.GetEntryChainCutoff(ctx)
+		if err != nil {
+			return err
+		}
+		tip, walkBreak, err := store.walkEntryChain(ctx, q, cutoff, accountID)
+		if err != nil || walkBreak != nil {
+			chainBreak = walkBreak
+			return err
+		}
+		if tip.count == 0 {
+			return nil
+		}
+		if tip.entryID >= cutoff.KeyedFromEntryID {
+			chainBreak = &ChainBreak{AccountID: accountID, EntryID: tip.entryID, Reason: "chain has no anchored head"}
+			return nil
+		}
+		return store.anchorHead(ctx, q, accountID, tip)
+	})
+	return chainBreak, err
+}
+
+// AnchorLedger anchors the chain of every account that has no head yet and
+// returns the first broken link of each account whose chain does not hold
+func (store *SQLStore) AnchorLedger(ctx context.Context) ([]ChainBreak, error) {
+	return store.forEachAccount(ctx, store.AnchorEntryChain)
+}
+
+// VerifyLedger verifies the hash chain of every account and returns the
+// first broken link of each account whose chain does not hold
+func (store *SQLStore) VerifyLedger(ctx context.Context) ([]ChainBreak, error) {
+	return store.forEachAccount(ctx, store.VerifyEntryChain)
+}
+
+// forEachAccount calls check for every account and collects the breaks it finds
+func (store *SQLStore) forEachAccount(ctx context.Context, check func(ctx context.Context, accountID int64) (*ChainBreak, error)) ([]ChainBreak, error) {
+	breaks := []ChainBreak{}
+	var afterID int64
+	for {
+		accountIDs, err := store.ListAccountIDs(ctx, ListAccountIDsParams{
+			AfterID: afterID,
+			Limit:   entryChainBatchSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, accountID := range accountIDs {
+			chainBreak, err := check(ctx, accountID)
+			if err != nil {
+				return nil, err
+			}
+			if chainBreak != nil {
+				breaks = append(breaks, *chainBreak)
+			}
+		}
+		if len(accountIDs) < entryChainBatchSize {
+			return breaks, nil
+		}
+		afterID = accountIDs[len(accountIDs)-1]
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -94,3 +95,179 @@ func TestListEntries(t *testing.T) {
 	}
 
 }
+
+func TestListEntriesAfter(t *testing.T) {
+	var entrylist []Entry
+	account, _ := createRandomAccount(t)
+
+	for i := 0; i < 10; i++ {
+		entry, _ := CreateRandomEntryWithFixedAccount(t, account)
+		entrylist = append(entrylist, entry)
+	}
+
+	arg := ListEntriesAfterParams{
+		AccountID: account.ID,
+		Limit:     5,
+	}
+	var entries []Entry
+	for {
+		page, err := testQueries.ListEntriesAfter(context.Background(), arg)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		require.LessOrEqual(t, len(page), 5)
+		entries = append(entries, page...)
+
+		last := page[len(page)-1]
+		arg.AfterCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		arg.AfterID = last.ID
+	}
+	require.Equal(t, entrylist, entries)
+}
+
+func TestEntryHashChain(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account1, _ := createRandomAccount(t)
+	account2, _ := createRandomAccount(t)
+
+	var results []TransferTxResult
+	for i := 0; i < 3; i++ {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1,
+		})
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+
+	// 每个账户的分录依次链接到上一条
+	require.Nil(t, results[0].FromEntry.PrevHash)
+	for i := 1; i < len(results); i++ {
+		require.Equal(t, results[i-1].FromEntry.Hash, results[i].FromEntry.PrevHash)
+		require.Equal(t, results[i-1].ToEntry.Hash, results[i].ToEntry.PrevHash)
+	}
+
+	chainBreak, err := store.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Nil(t, chainBreak)
+
+	// 篡改中间一条分录的金额
+	tampered := results[1].FromEntry
+	_, err = testDB.Exec("UPDATE entries SET amount = amount - 1 WHERE id = $1", tampered.ID)
+	require.NoError(t, err)
+
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, tampered.ID, chainBreak.EntryID)
+
+	// 删除中间的分录同样会断链
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Nil(t, chainBreak)
+
+	_, err = testDB.Exec("DELETE FROM entries WHERE id = $1", results[1].ToEntry.ID)
+	require.NoError(t, err)
+
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, results[2].ToEntry.ID, chainBreak.EntryID)
+}
+
+func TestEntryHashChainTail(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account1, _ := createRandomAccount(t)
+	account2, _ := createRandomAccount(t)
+
+	var results []TransferTxResult
+	for i := 0; i < 3; i++ {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1,
+		})
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+
+	// 删掉链尾的分录，剩下的链本身依然完整
+	_, err := testDB.Exec("DELETE FROM entries WHERE id = $1", results[2].FromEntry.ID)
+	require.NoError(t, err)
+
+	chainBreak, err := store.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, results[2].FromEntry.ID, chainBreak.EntryID)
+
+	// 把锚点一起改回去也不行，没有密钥算不出它的mac
+	_, err = testDB.Exec("UPDATE entry_chain_heads SET head_entry_id = $1, head_hash = $2, entry_count = entry_count - 1 WHERE account_id = $3",
+		results[1].FromEntry.ID, results[1].FromEntry.Hash, account1.ID)
+	require.NoError(t, err)
+
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, "anchored head does not match its mac", chainBreak.Reason)
+
+	// 清空所有哈希也是断链
+	_, err = testDB.Exec("UPDATE entries SET prev_hash = NULL, hash = NULL WHERE account_id = $1", account2.ID)
+	require.NoError(t, err)
+
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, results[0].ToEntry.ID, chainBreak.EntryID)
+	require.Equal(t, "entry has no hash", chainBreak.Reason)
+}
+
+func TestEntryHashChainKey(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account1, _ := createRandomAccount(t)
+	account2, _ := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	// 用另一个密钥校验，哈希和锚点都对不上
+	otherStore := NewStore(testDB, utils.RandomString(MinLedgerHashKeySize))
+	chainBreak, err := otherStore.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, "hash does not match the content of the entry", chainBreak.Reason)
+
+	// 已有带密钥分录的链不能重新锚定
+	_, err = testDB.Exec("DELETE FROM entry_chain_heads WHERE account_id = $1", account1.ID)
+	require.NoError(t, err)
+
+	chainBreak, err = store.AnchorEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+	require.Equal(t, "chain has no anchored head", chainBreak.Reason)
+
+	chainBreak, err = store.VerifyEntryChain(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, chainBreak)
+}
+
+func TestEntryHash(t *testing.T) {
+	createdAt := time.Now()
+	transferID := sql.NullInt64{Int64: 1, Valid: true}
+	key := []byte(testLedgerHashKey)
+	hash := EntryHash(key, nil, 1, 10, transferID, sql.NullInt64{}, createdAt)
+	require.Len(t, hash, 32)
+	require.Equal(t, hash, EntryHash(key, nil, 1, 10, transferID, sql.NullInt64{}, createdAt))
+	require.NotEqual(t, hash, EntryHash(key, nil, 1, 11, transferID, sql.NullInt64{}, createdAt))
+	require.NotEqual(t, hash, EntryHash(key, hash, 1, 10, transferID, sql.NullInt64{}, createdAt))
+	require.NotEqual(t, hash, EntryHash(key, nil, 1, 10, sql.NullInt64{}, sql.NullInt64{}, createdAt))
+	require.NotEqual(t, hash, EntryHash(key, nil, 1, 10, transferID, sql.NullInt64{Int64: 1, Valid: true}, createdAt))
+	// 不同的密钥以及密钥生效之前的分录得到不同的哈希
+	require.NotEqual(t, hash, EntryHash([]byte(utils.RandomString(MinLedgerHashKeySize)), nil, 1, 10, transferID, sql.NullInt64{}, createdAt))
+	require.NotEqual(t, hash, EntryHash(nil, nil, 1, 10, transferID, sql.NullInt64{}, createdAt))
+}
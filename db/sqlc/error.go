@@ -0,0 +1,46 @@
+package db
+
+import "errors"
+
+// ErrInsufficientFunds is returned when a transfer would take the source
+// account below its overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrSystemAccount is returned when a customer operation, such as a transfer,
+// involves one of the accounts of the bank
+var ErrSystemAccount = errors.New("accounts of the bank can't take part in customer transactions")
+
+// ErrInvalidJournal is returned when a journal has fewer than two legs or a
+// leg without an amount
+var ErrInvalidJournal = errors.New("journal must have at least two legs with non-zero amounts")
+
+// ErrUnbalancedJournal is returned when the legs of a journal don't sum to
+// zero in every currency
+var ErrUnbalancedJournal = errors.New("journal legs must sum to zero in every currency")
+
+// ErrIdempotencyKeyConflict is returned when an idempotency key is reused
+// with a request that differs from the one it was first used with
+var ErrIdempotencyKeyConflict = errors.New("idempotency key was already used for a different request")
+
+// ErrRefreshTokenReused is returned when a refresh token that was already
+// rotated is presented again; its whole session family has been blocked
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+// ErrSessionBlocked is returned when a refresh token belongs to a session
+// that has been blocked
+var ErrSessionBlocked = errors.New("session is blocked")
+
+// ErrQuoteNotFound is returned when a transfer quote doesn't exist or belongs
+// to another user
+var ErrQuoteNotFound = errors.New("transfer quote not found")
+
+// ErrQuoteMismatch is returned when a transfer doesn't match the accounts and
+// amount of its quote
+var ErrQuoteMismatch = errors.New("transfer does not match its quote")
+
+// ErrQuoteExpired is returned when a transfer quote is used after it expired
+var ErrQuoteExpired = errors.New("transfer quote has expired")
+
+// ErrQuoteUsed is returned when a transfer quote was already used by another
+// transfer
+var ErrQuoteUsed = errors.New("transfer quote has already been used")
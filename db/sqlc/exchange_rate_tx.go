@@ -0,0 +1,25 @@
+package db
+
+import "context"
+
+// CreateExchangeRatesTx appends a batch of rates fetched together. Either the
+// whole batch is saved or none of it, so the latest rates of the pairs always
+// come from the same fetch.
+func (store *SQLStore) CreateExchangeRatesTx(ctx context.Context, args []CreateExchangeRateParams) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+	err := store.exeTx(ctx, func(q *Queries) error {
+		rates = make([]ExchangeRate, 0, len(args))
+		for _, arg := range args {
+			rate, err := q.CreateExchangeRate(ctx, arg)
+			if err != nil {
+				return err
+			}
+			rates = append(rates, rate)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateExchangeRatesTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	asOf := time.Now().Truncate(time.Microsecond)
+	latest := func() ExchangeRate {
+		rate, err := testQueries.GetLatestExchangeRate(context.Background(), GetLatestExchangeRateParams{
+			BaseCurrency:  "CAD",
+			QuoteCurrency: "GBP",
+		})
+		require.NoError(t, err)
+		return rate
+	}
+
+	rates, err := store.CreateExchangeRatesTx(context.Background(), []CreateExchangeRateParams{
+		{BaseCurrency: "CAD", QuoteCurrency: "GBP", Rate: "0.58", AsOf: asOf},
+		{BaseCurrency: "GBP", QuoteCurrency: "CAD", Rate: "1.72", AsOf: asOf},
+	})
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	require.Equal(t, rates[0].CreatedAt, rates[1].CreatedAt)
+	require.Equal(t, rates[0].ID, latest().ID)
+	require.True(t, asOf.Equal(rates[0].AsOf))
+
+	// 后面的汇率写入失败时，前面的也不会保存
+	_, err = store.CreateExchangeRatesTx(context.Background(), []CreateExchangeRateParams{
+		{BaseCurrency: "CAD", QuoteCurrency: "GBP", Rate: "0.59", AsOf: asOf},
+		{BaseCurrency: "GBP", QuoteCurrency: "CAD", Rate: "0", AsOf: asOf},
+	})
+	require.Error(t, err)
+	require.Equal(t, rates[0].ID, latest().ID)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerFXTransfer is the type of ledger transactions posted by FXTransferTx
+const LedgerFXTransfer = "fx_transfer"
+
+type CreateTransferQuoteTxParams struct {
+	CreateTransferQuoteParams
+	Audit *AuditParams `json:"-"`
+}
+
+// CreateTransferQuoteTx locks an exchange rate for a transfer
+func (store *SQLStore) CreateTransferQuoteTx(ctx context.Context, arg CreateTransferQuoteTxParams) (TransferQuote, error) {
+	var quote TransferQuote
+	err := store.exeTx(ctx, func(q *Queries) error {
+		var err error
+		quote, err = q.CreateTransferQuote(ctx, arg.CreateTransferQuoteParams)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditQuoteCreate, AuditTargetTransferQuote, quote.ID.String(), nil, quote)
+	})
+	return quote, err
+}
+
+// FXTransferTxParams transfers money between accounts in different
+// currencies at the rate locked by a quote. The accounts and amount must be
+// the ones the quote was given for.
+type FXTransferTxParams struct {
+	QuoteID       uuid.UUID `json:"quote_id"`
+	Username      string    `json:"username"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	// Idempotency is optional; a replayed key returns the original result
+	Idempotency *IdempotencyParams `json:"-"`
+	Audit       *AuditParams       `json:"-"`
+}
+
+type FXTransferTxResult struct {
+	Quote       TransferQuote     `json:"quote"`
+	Transfer    Transfer          `json:"transfer"`
+	Transaction LedgerTransaction `json:"transaction"`
+	FromAccount Account           `json:"from_account"`
+	ToAccount   Account           `json:"to_account"`
+	FromEntry   Entry             `json:"from_entry"`
+	ToEntry     Entry             `json:"to_entry"`
+}
+
+// FXTransferTx uses the quote once and posts four legs: the amount leaves the
+// source account into the fx account of its currency, and the converted
+// amount leaves the fx account of the destination currency into the
+// destination account. The customer legs belong to a transfer, so it shows up
+// in the transfer history and statements like any other.
+func (store *SQLStore) FXTransferTx(ctx context.Context, arg FXTransferTxParams) (FXTransferTxResult, error) {
+	var result FXTransferTxResult
+	err := store.idempotentTx(ctx, arg.Idempotency, LedgerFXTransfer, arg, &result, func(q *Queries) error {
+		// 锁住报价，同一个报价只能用一次
+		quote, err := q.GetTransferQuoteForUpdate(ctx, arg.QuoteID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrQuoteNotFound
+			}
+			return err
+		}
+		switch {
+		case quote.Username != arg.Username:
+			return ErrQuoteNotFound
+		case quote.FromAccountID != arg.FromAccountID || quote.ToAccountID != arg.ToAccountID || quote.Amount != arg.Amount:
+			return ErrQuoteMismatch
+		case quote.UsedAt.Valid:
+			return ErrQuoteUsed
+		case time.Now().After(quote.ExpiresAt):
+			return ErrQuoteExpired
+		}
+
+		fromAccount, err := q.GetAccount(ctx, quote.FromAccountID)
+		if err != nil {
+			return err
+		}
+		toAccount, err := q.GetAccount(ctx, quote.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if IsBankAccount(fromAccount.Owner) || IsBankAccount(toAccount.Owner) {
+			return ErrSystemAccount
+		}
+
+		fxFromAccount, err := bankAccount(ctx, q, FXUsername, fromAccount.Currency)
+		if err != nil {
+			return err
+		}
+		fxToAccount, err := bankAccount(ctx, q, FXUsername, toAccount.Currency)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.CreateFXTransfer(ctx, CreateFXTransferParams{
+			FromAccountID:   quote.FromAccountID,
+			ToAccountID:     quote.ToAccountID,
+			Amount:          quote.Amount,
+			ConvertedAmount: sql.NullInt64{Int64: quote.ConvertedAmount, Valid: true},
+			QuoteID:         uuid.NullUUID{UUID: quote.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+
+		journal, err := store.postJournal(ctx, q, CreateLedgerTransactionParams{
+			Type:      LedgerFXTransfer,
+			AccountID: sql.NullInt64{Int64: quote.FromAccountID, Valid: true},
+			Amount:    sql.NullInt64{Int64: quote.Amount, Valid: true},
+		}, []JournalLeg{
+			{AccountID: fromAccount.ID, Amount: -quote.Amount, transferID: transferID},
+			{AccountID: fxFromAccount.ID, Amount: quote.Amount},
+			{AccountID: fxToAccount.ID, Amount: -quote.ConvertedAmount},
+			{AccountID: toAccount.ID, Amount: quote.ConvertedAmount, transferID: transferID},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Quote, err = q.UseTransferQuote(ctx, UseTransferQuoteParams{
+			ID:                  quote.ID,
+			LedgerTransactionID: sql.NullInt64{Int64: journal.Transaction.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		result.Transaction = journal.Transaction
+		result.FromAccount = journal.Accounts[0]
+		result.ToAccount = journal.Accounts[3]
+		result.FromEntry = journal.Entries[0]
+		result.ToEntry = journal.Entries[3]
+
+		return recordAudit(ctx, q, arg.Audit, AuditFXTransfer, AuditTargetLedgerTransaction, auditID(result.Transaction.ID), nil, result.Quote)
+	})
+	return result, err
+}
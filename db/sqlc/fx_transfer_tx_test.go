@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomQuote(t *testing.T, from Account, to Account, amount int64, convertedAmount int64, expiresAt time.Time) TransferQuote {
+	rate, err := testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+		BaseCurrency:  from.Currency,
+		QuoteCurrency: to.Currency,
+		Rate:          "0.9",
+		AsOf:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	quote, err := testQueries.CreateTransferQuote(context.Background(), CreateTransferQuoteParams{
+		ID:              uuid.New(),
+		Username:        from.Owner,
+		FromAccountID:   from.ID,
+		ToAccountID:     to.ID,
+		Amount:          amount,
+		ConvertedAmount: convertedAmount,
+		ExchangeRateID:  rate.ID,
+		Rate:            rate.Rate,
+		ExpiresAt:       expiresAt,
+	})
+	require.NoError(t, err)
+	return quote
+}
+
+func TestGetLatestExchangeRate(t *testing.T) {
+	for _, rate := range []string{"1.1", "1.2"} {
+		_, err := testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+			BaseCurrency:  "GBP",
+			QuoteCurrency: "JPY",
+			Rate:          rate,
+			AsOf:          time.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	rate, err := testQueries.GetLatestExchangeRate(context.Background(), GetLatestExchangeRateParams{
+		BaseCurrency:  "GBP",
+		QuoteCurrency: "JPY",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.200000000000", rate.Rate)
+}
+
+func TestFXTransferTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	from := createFundedAccount(t, "USD", 1000)
+	to := createFundedAccount(t, "EUR", 0)
+	quote := createRandomQuote(t, from, to, 100, 90, time.Now().Add(time.Minute))
+
+	arg := FXTransferTxParams{
+		QuoteID:       quote.ID,
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	}
+	result, err := store.FXTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, LedgerFXTransfer, result.Transaction.Type)
+	require.Equal(t, int64(900), result.FromAccount.Balance)
+	require.Equal(t, int64(90), result.ToAccount.Balance)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(90), result.ToEntry.Amount)
+	require.True(t, result.Quote.UsedAt.Valid)
+	require.Equal(t, result.Transaction.ID, result.Quote.LedgerTransactionID.Int64)
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, int64(90), result.Transfer.ConvertedAmount.Int64)
+	require.Equal(t, quote.ID, result.Transfer.QuoteID.UUID)
+	require.Equal(t, result.Transfer.ID, result.FromEntry.TransferID.Int64)
+	require.Equal(t, result.Transfer.ID, result.ToEntry.TransferID.Int64)
+
+	// 换汇转账和普通转账一样出现在转出账户的转账记录和对账单里
+	transfers, err := testQueries.ListTransfers(context.Background(), ListTransfersParams{
+		FromAccountID: from.ID,
+		ToAccountID:   from.ID,
+		Limit:         5,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, result.Transfer, transfers[0])
+
+	lines, err := testQueries.ListStatementEntries(context.Background(), ListStatementEntriesParams{
+		AccountID: to.ID,
+		StartTime: result.Transfer.CreatedAt.Add(-time.Minute),
+		EndTime:   time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	require.Equal(t, from.ID, lines[0].CounterpartyAccountID)
+
+	_, err = store.FXTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrQuoteUsed)
+
+	report, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	for _, transaction := range report.UnbalancedLedgerTransactions {
+		require.NotEqual(t, result.Transaction.ID, transaction.LedgerTransactionID)
+	}
+	for _, transfer := range report.UnmatchedTransfers {
+		require.NotEqual(t, result.Transfer.ID, transfer.TransferID)
+	}
+}
+
+func TestFXTransferTxRejected(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	from := createFundedAccount(t, "USD", 1000)
+	to := createFundedAccount(t, "EUR", 0)
+	quote := createRandomQuote(t, from, to, 100, 90, time.Now().Add(time.Minute))
+	expired := createRandomQuote(t, from, to, 100, 90, time.Now().Add(-time.Second))
+	tooLarge := createRandomQuote(t, from, to, 2000, 1800, time.Now().Add(time.Minute))
+
+	testCases := []struct {
+		name   string
+		modify func(arg *FXTransferTxParams)
+		err    error
+	}{
+		{
+			name:   "UnknownQuote",
+			modify: func(arg *FXTransferTxParams) { arg.QuoteID = uuid.New() },
+			err:    ErrQuoteNotFound,
+		},
+		{
+			name:   "OtherUser",
+			modify: func(arg *FXTransferTxParams) { arg.Username = to.Owner },
+			err:    ErrQuoteNotFound,
+		},
+		{
+			name:   "OtherAmount",
+			modify: func(arg *FXTransferTxParams) { arg.Amount = 101 },
+			err:    ErrQuoteMismatch,
+		},
+		{
+			name:   "Expired",
+			modify: func(arg *FXTransferTxParams) { arg.QuoteID = expired.ID },
+			err:    ErrQuoteExpired,
+		},
+		{
+			name: "InsufficientFunds",
+			modify: func(arg *FXTransferTxParams) {
+				arg.QuoteID = tooLarge.ID
+				arg.Amount = tooLarge.Amount
+			},
+			err: ErrInsufficientFunds,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			arg := FXTransferTxParams{
+				QuoteID:       quote.ID,
+				Username:      from.Owner,
+				FromAccountID: from.ID,
+				ToAccountID:   to.ID,
+				Amount:        quote.Amount,
+			}
+			tc.modify(&arg)
+			_, err := store.FXTransferTx(context.Background(), arg)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// IdempotencyParams identifies a client request that may be retried.
+// Keys are scoped per user, so two users may pick the same key.
+type IdempotencyParams struct {
+	Username string
+	Key      string
+}
+
+// idempotentTx runs fn in a transaction guarded by the idempotency key.
+// The first request with a key stores the result it produced; a replay of the
+// same request gets the stored result back without running fn again, and a
+// replay with a different request fails with ErrIdempotencyKeyConflict.
+// Without a key it behaves like exeTx.
+func (store *SQLStore) idempotentTx(ctx context.Context, idem *IdempotencyParams, operation string, request any, result any, fn func(q *Queries) error) error {
+	if idem == nil {
+		return store.exeTx(ctx, fn)
+	}
+
+	requestHash, err := hashRequest(operation, request)
+	if err != nil {
+		return err
+	}
+
+	return store.exeTx(ctx, func(q *Queries) error {
+		//同一个key的请求串行执行，直到持有锁的事务提交
+		err := q.LockIdempotencyKey(ctx, LockIdempotencyKeyParams{
+			Username: idem.Username,
+			Key:      idem.Key,
+		})
+		if err != nil {
+			return err
+		}
+
+		saved, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+			Username:       idem.Username,
+			IdempotencyKey: idem.Key,
+		})
+		if err == nil {
+			if saved.RequestHash != requestHash {
+				return ErrIdempotencyKeyConflict
+			}
+			return json.Unmarshal(saved.Response, result)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if err := fn(q); err != nil {
+			return err
+		}
+
+		response, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:       idem.Username,
+			IdempotencyKey: idem.Key,
+			RequestHash:    requestHash,
+			Response:       response,
+		})
+		return err
+	})
+}
+
+func hashRequest(operation string, request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(operation+":"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
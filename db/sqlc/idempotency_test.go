@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func TestTransferTxIdempotent(t *testing.T) {
+	testStore := NewStore(testDB, testLedgerHashKey)
+
+	account1 := createFundedAccount(t, "USD", 1000)
+	account2 := createFundedAccount(t, "USD", 1000)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Idempotency: &IdempotencyParams{
+			Username: account1.Owner,
+			Key:      utils.RandomString(16),
+		},
+	}
+
+	result1, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	result2, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromEntry.ID, result2.FromEntry.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+
+	updateFromAccount, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updateFromAccount.Balance)
+
+	arg.Amount = 20
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+}
+
+func TestTransferTxIdempotentConcurrent(t *testing.T) {
+	testStore := NewStore(testDB, testLedgerHashKey)
+
+	account1 := createFundedAccount(t, "USD", 1000)
+	account2 := createFundedAccount(t, "USD", 1000)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Idempotency: &IdempotencyParams{
+			Username: account1.Owner,
+			Key:      utils.RandomString(16),
+		},
+	}
+
+	n := 5
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := testStore.TransferTx(context.Background(), arg)
+			errs <- err
+			results <- result
+		}()
+	}
+
+	transferIDs := make(map[int64]bool)
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+		result := <-results
+		transferIDs[result.Transfer.ID] = true
+	}
+	require.Len(t, transferIDs, 1)
+
+	updateFromAccount, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updateFromAccount.Balance)
+}
+
+func TestCreateAccountTxIdempotent(t *testing.T) {
+	testStore := NewStore(testDB, testLedgerHashKey)
+
+	user, err := createRandomUser(t)
+	require.NoError(t, err)
+
+	arg := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  0,
+			Currency: utils.RandomCurrency(),
+		},
+		Idempotency: &IdempotencyParams{
+			Username: user.Username,
+			Key:      utils.RandomString(16),
+		},
+	}
+
+	account1, err := testStore.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	account2, err := testStore.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, 0)
+}
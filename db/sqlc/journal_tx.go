@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+)
+
+// LedgerJournal is the type of ledger transactions posted by PostJournalTx
+const LedgerJournal = "journal"
+
+// JournalLeg is one side of a journal posting. A negative amount debits the
+// account, a positive one credits it.
+type JournalLeg struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// transferID links the entry to a transfer; only FXTransferTx sets it, so
+	// callers of PostJournalTx can't attach their legs to someone's transfer
+	transferID sql.NullInt64
+}
+
+type PostJournalTxParams struct {
+	// Legs must sum to zero in every currency
+	Legs []JournalLeg `json:"legs"`
+	// Idempotency is optional; a replayed key returns the original result
+	Idempotency *IdempotencyParams `json:"-"`
+	Audit       *AuditParams       `json:"-"`
+}
+
+type PostJournalTxResult struct {
+	Transaction LedgerTransaction `json:"transaction"`
+	// Entries follow the order of the legs
+	Entries []Entry `json:"entries"`
+	// Accounts follow the order of the legs, with their balance after the
+	// whole posting
+	Accounts []Account `json:"accounts"`
+}
+
+// PostJournalTx posts any number of legs as a single ledger transaction, so
+// that fees, splits and conversions commit or roll back together
+func (store *SQLStore) PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+	err := store.idempotentTx(ctx, arg.Idempotency, LedgerJournal, arg, &result, func(q *Queries) error {
+		var err error
+		result, err = store.postJournal(ctx, q, CreateLedgerTransactionParams{Type: LedgerJournal}, arg.Legs)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditJournalPost, AuditTargetLedgerTransaction, auditID(result.Transaction.ID), nil, arg.Legs)
+	})
+	return result, err
+}
+
+// postJournal creates the ledger transaction and appends an entry for every
+// leg. The accounts are locked in ascending id order, as for the two accounts
+// of a transfer, so concurrent postings can't deadlock. Accounts of customers
+// may not go below their overdraft limit; the accounts of the bank may.
+func (store *SQLStore) postJournal(ctx context.Context, q *Queries, transaction CreateLedgerTransactionParams, legs []JournalLeg) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+	if len(legs) < 2 {
+		return result, ErrInvalidJournal
+	}
+	net := make(map[int64]int64, len(legs))
+	for _, leg := range legs {
+		if leg.Amount == 0 {
+			return result, ErrInvalidJournal
+		}
+		net[leg.AccountID] += leg.Amount
+	}
+
+	accountIDs := slices.Sorted(maps.Keys(net))
+	accounts := make(map[int64]Account, len(accountIDs))
+	totals := make(map[string]int64)
+	for _, accountID := range accountIDs {
+		account, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return result, err
+		}
+		accounts[accountID] = account
+		totals[account.Currency] += net[accountID]
+	}
+	for _, total := range totals {
+		if total != 0 {
+			return result, ErrUnbalancedJournal
+		}
+	}
+	for _, accountID := range accountIDs {
+		account := accounts[accountID]
+		if !IsBankAccount(account.Owner) && net[accountID] < 0 && account.Balance+net[accountID] < -account.OverdraftLimit {
+			return result, ErrInsufficientFunds
+		}
+	}
+
+	var err error
+	result.Transaction, err = q.CreateLedgerTransaction(ctx, transaction)
+	if err != nil {
+		return result, err
+	}
+	ledgerTransactionID := sql.NullInt64{Int64: result.Transaction.ID, Valid: true}
+
+	result.Entries = make([]Entry, 0, len(legs))
+	for _, leg := range legs {
+		entry, err := store.appendEntry(ctx, q, CreateChainedEntryParams{
+			AccountID:           leg.AccountID,
+			Amount:              leg.Amount,
+			TransferID:          leg.transferID,
+			LedgerTransactionID: ledgerTransactionID,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	for _, accountID := range accountIDs {
+		if net[accountID] == 0 {
+			continue
+		}
+		accounts[accountID], err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			Amount:    net[accountID],
+			AccountID: accountID,
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.Accounts = make([]Account, 0, len(legs))
+	for _, leg := range legs {
+		result.Accounts = append(result.Accounts, accounts[leg.AccountID])
+	}
+	return result, nil
+}
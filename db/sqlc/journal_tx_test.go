@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJournalTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account1 := createFundedAccount(t, "USD", 100)
+	account2 := createFundedAccount(t, "USD", 0)
+	account3 := createFundedAccount(t, "USD", 0)
+
+	result, err := store.PostJournalTx(context.Background(), PostJournalTxParams{
+		Legs: []JournalLeg{
+			{AccountID: account1.ID, Amount: -100},
+			{AccountID: account2.ID, Amount: 70},
+			{AccountID: account3.ID, Amount: 30},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, LedgerJournal, result.Transaction.Type)
+	require.False(t, result.Transaction.AccountID.Valid)
+	require.False(t, result.Transaction.Amount.Valid)
+
+	require.Len(t, result.Entries, 3)
+	require.Len(t, result.Accounts, 3)
+	for i, amount := range []int64{-100, 70, 30} {
+		require.Equal(t, amount, result.Entries[i].Amount)
+		require.Equal(t, result.Transaction.ID, result.Entries[i].LedgerTransactionID.Int64)
+	}
+	require.Equal(t, int64(0), result.Accounts[0].Balance)
+	require.Equal(t, int64(70), result.Accounts[1].Balance)
+	require.Equal(t, int64(30), result.Accounts[2].Balance)
+}
+
+func TestPostJournalTxRejected(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	usd1 := createFundedAccount(t, "USD", 100)
+	usd2 := createFundedAccount(t, "USD", 100)
+	eur := createFundedAccount(t, "EUR", 100)
+
+	testCases := []struct {
+		name string
+		legs []JournalLeg
+		err  error
+	}{
+		{
+			name: "OneLeg",
+			legs: []JournalLeg{{AccountID: usd1.ID, Amount: 10}},
+			err:  ErrInvalidJournal,
+		},
+		{
+			name: "ZeroAmount",
+			legs: []JournalLeg{{AccountID: usd1.ID, Amount: 0}, {AccountID: usd2.ID, Amount: 0}},
+			err:  ErrInvalidJournal,
+		},
+		{
+			name: "Unbalanced",
+			legs: []JournalLeg{{AccountID: usd1.ID, Amount: -10}, {AccountID: usd2.ID, Amount: 5}},
+			err:  ErrUnbalancedJournal,
+		},
+		{
+			name: "UnbalancedPerCurrency",
+			legs: []JournalLeg{{AccountID: usd1.ID, Amount: -10}, {AccountID: eur.ID, Amount: 10}},
+			err:  ErrUnbalancedJournal,
+		},
+		{
+			name: "InsufficientFunds",
+			legs: []JournalLeg{{AccountID: usd1.ID, Amount: -101}, {AccountID: usd2.ID, Amount: 101}},
+			err:  ErrInsufficientFunds,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.PostJournalTx(context.Background(), PostJournalTxParams{Legs: tc.legs})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	account, err := testQueries.GetAccount(context.Background(), usd1.ID)
+	require.NoError(t, err)
+	require.Equal(t, usd1.Balance, account.Balance)
+}
+
+func TestPostJournalTxDeadlock(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account1 := createFundedAccount(t, "USD", 1000)
+	account2 := createFundedAccount(t, "USD", 1000)
+	account3 := createFundedAccount(t, "USD", 1000)
+
+	n := 10
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		// 每个方向的记账顺序都不同，没有按id加锁就会死锁
+		legs := []JournalLeg{
+			{AccountID: account3.ID, Amount: -2},
+			{AccountID: account1.ID, Amount: 1},
+			{AccountID: account2.ID, Amount: 1},
+		}
+		if i%2 == 1 {
+			legs = []JournalLeg{
+				{AccountID: account1.ID, Amount: -1},
+				{AccountID: account2.ID, Amount: -1},
+				{AccountID: account3.ID, Amount: 2},
+			}
+		}
+		go func() {
+			_, err := store.PostJournalTx(context.Background(), PostJournalTxParams{Legs: legs})
+			errs <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	for _, account := range []Account{account1, account2, account3} {
+		updated, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updated.Balance)
+	}
+}
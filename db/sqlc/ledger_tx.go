@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Types of ledger transactions
+const (
+	LedgerDeposit    = "deposit"
+	LedgerWithdrawal = "withdrawal"
+)
+
+// Users owning the accounts of the bank itself, one account per currency
+// each. They are disabled and have no password, so nobody can log in as them.
+const (
+	// SystemUsername owns the cash accounts funding deposits and withdrawals
+	SystemUsername = "system"
+	// FXUsername owns the accounts crossing currencies in fx transfers
+	FXUsername = "fx"
+)
+
+// IsBankAccount reports whether an account owned by owner belongs to the bank
+func IsBankAccount(owner string) bool {
+	return owner == SystemUsername || owner == FXUsername
+}
+
+// bankAccount returns the account of the bank user owner in currency,
+// creating it the first time the currency is used
+func bankAccount(ctx context.Context, q *Queries, owner string, currency string) (Account, error) {
+	err := q.CreateAccountIfNotExists(ctx, CreateAccountIfNotExistsParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+	if err != nil {
+		return Account{}, err
+	}
+	return q.GetOwnerAccount(ctx, GetOwnerAccountParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+}
+
+// LedgerTxParams moves money between a customer account and the cash
+// account of the bank in the same currency
+type LedgerTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// Idempotency is optional; a replayed key returns the original result
+	Idempotency *IdempotencyParams `json:"-"`
+	Audit       *AuditParams       `json:"-"`
+}
+
+type LedgerTxResult struct {
+	Transaction   LedgerTransaction `json:"transaction"`
+	Account       Account           `json:"account"`
+	SystemAccount Account           `json:"system_account"`
+	Entry         Entry             `json:"entry"`
+	SystemEntry   Entry             `json:"system_entry"`
+}
+
+// DepositTx credits the account and debits the cash account of the bank
+func (store *SQLStore) DepositTx(ctx context.Context, arg LedgerTxParams) (LedgerTxResult, error) {
+	return store.ledgerTx(ctx, LedgerDeposit, arg)
+}
+
+// WithdrawTx debits the account, within its overdraft limit, and credits the
+// cash account of the bank
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg LedgerTxParams) (LedgerTxResult, error) {
+	return store.ledgerTx(ctx, LedgerWithdrawal, arg)
+}
+
+func (store *SQLStore) ledgerTx(ctx context.Context, txType string, arg LedgerTxParams) (LedgerTxResult, error) {
+	var result LedgerTxResult
+	err := store.idempotentTx(ctx, arg.Idempotency, txType, arg, &result, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if IsBankAccount(account.Owner) {
+			return ErrSystemAccount
+		}
+
+		systemAccount, err := bankAccount(ctx, q, SystemUsername, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		amount := arg.Amount
+		if txType == LedgerWithdrawal {
+			amount = -arg.Amount
+		}
+		journal, err := store.postJournal(ctx, q, CreateLedgerTransactionParams{
+			Type:      txType,
+			AccountID: sql.NullInt64{Int64: arg.AccountID, Valid: true},
+			Amount:    sql.NullInt64{Int64: arg.Amount, Valid: true},
+		}, []JournalLeg{
+			{AccountID: account.ID, Amount: amount},
+			{AccountID: systemAccount.ID, Amount: -amount},
+		})
+		if err != nil {
+			return err
+		}
+		result = LedgerTxResult{
+			Transaction:   journal.Transaction,
+			Account:       journal.Accounts[0],
+			SystemAccount: journal.Accounts[1],
+			Entry:         journal.Entries[0],
+			SystemEntry:   journal.Entries[1],
+		}
+
+		action := AuditAccountDeposit
+		if txType == LedgerWithdrawal {
+			action = AuditAccountWithdraw
+		}
+		return recordAudit(ctx, q, arg.Audit, action, AuditTargetLedgerTransaction, auditID(result.Transaction.ID), nil, result.Transaction)
+	})
+	return result, err
+}
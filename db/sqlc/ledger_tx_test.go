@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositWithdrawTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account := createFundedAccount(t, "USD", 0)
+
+	deposit, err := store.DepositTx(context.Background(), LedgerTxParams{
+		AccountID: account.ID,
+		Amount:    100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, LedgerDeposit, deposit.Transaction.Type)
+	require.Equal(t, account.ID, deposit.Transaction.AccountID.Int64)
+	require.Equal(t, int64(100), deposit.Transaction.Amount.Int64)
+	require.Equal(t, int64(100), deposit.Account.Balance)
+	require.Equal(t, int64(100), deposit.Entry.Amount)
+	require.Equal(t, deposit.Transaction.ID, deposit.Entry.LedgerTransactionID.Int64)
+	require.Equal(t, SystemUsername, deposit.SystemAccount.Owner)
+	require.Equal(t, account.Currency, deposit.SystemAccount.Currency)
+	require.Equal(t, int64(-100), deposit.SystemEntry.Amount)
+	require.Equal(t, deposit.Transaction.ID, deposit.SystemEntry.LedgerTransactionID.Int64)
+
+	withdrawal, err := store.WithdrawTx(context.Background(), LedgerTxParams{
+		AccountID: account.ID,
+		Amount:    30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, LedgerWithdrawal, withdrawal.Transaction.Type)
+	require.Equal(t, int64(70), withdrawal.Account.Balance)
+	require.Equal(t, int64(-30), withdrawal.Entry.Amount)
+	require.Equal(t, int64(30), withdrawal.SystemEntry.Amount)
+	require.Equal(t, deposit.SystemAccount.ID, withdrawal.SystemAccount.ID)
+	require.Equal(t, deposit.SystemAccount.Balance+30, withdrawal.SystemAccount.Balance)
+
+	_, err = store.WithdrawTx(context.Background(), LedgerTxParams{
+		AccountID: account.ID,
+		Amount:    71,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	chainBreak, err := store.VerifyEntryChain(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Nil(t, chainBreak)
+
+	report, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, balanceMismatchIDs(report), account.ID)
+	require.NotContains(t, balanceMismatchIDs(report), deposit.SystemAccount.ID)
+	for _, transaction := range report.UnbalancedLedgerTransactions {
+		require.NotEqual(t, account.ID, transaction.AccountID.Int64)
+	}
+}
+
+func TestTransferTxSystemAccount(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	account := createFundedAccount(t, "USD", 0)
+
+	deposit, err := store.DepositTx(context.Background(), LedgerTxParams{
+		AccountID: account.ID,
+		Amount:    100,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   deposit.SystemAccount.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	_, err = store.DepositTx(context.Background(), LedgerTxParams{
+		AccountID: deposit.SystemAccount.ID,
+		Amount:    10,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,14 +18,104 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// username of the user who made the change
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// state of the target before the change, null when it was created
+	Before json.RawMessage `json:"before"`
+	// state of the target after the change
+	After     json.RawMessage `json:"after"`
+	ClientIp  string          `json:"client_ip"`
+	UserAgent string          `json:"user_agent"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 code
+	Code string `json:"code"`
+	// decimals of the major unit; amounts are stored in the minor unit
+	MinorUnits int32 `json:"minor_units"`
+	// disabled currencies keep their accounts but can't be used for new ones
+	Enabled bool `json:"enabled"`
 }
 
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be negative
-	Amount    int64     `json:"amount"`
+	Amount     int64         `json:"amount"`
+	CreatedAt  time.Time     `json:"created_at"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// hash of the previous entry of the account, null for the first entry of the chain
+	PrevHash []byte `json:"prev_hash"`
+	// hmac-sha256 with LEDGER_HASH_KEY over the entry and prev_hash, plain sha256 before keyed_from_entry_id, null for entries written before the chain existed
+	Hash                []byte        `json:"hash"`
+	LedgerTransactionID sql.NullInt64 `json:"ledger_transaction_id"`
+}
+
+type EntryChainCutoff struct {
+	Singleton bool `json:"singleton"`
+	// entries from this id on must have a hash
+	HashedFromEntryID int64 `json:"hashed_from_entry_id"`
+	// entries from this id on are hashed with LEDGER_HASH_KEY, earlier ones with plain sha256
+	KeyedFromEntryID int64 `json:"keyed_from_entry_id"`
+}
+
+type EntryChainHead struct {
+	AccountID int64 `json:"account_id"`
+	// last entry of the chain of the account
+	HeadEntryID int64  `json:"head_entry_id"`
+	HeadHash    []byte `json:"head_hash"`
+	// number of hashed entries of the account
+	EntryCount int64 `json:"entry_count"`
+	// hmac over the other columns with LEDGER_HASH_KEY
+	Mac       []byte    `json:"mac"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExchangeRate struct {
+	ID            int64  `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// amount of quote currency for one unit of base currency
+	Rate      string    `json:"rate"`
 	CreatedAt time.Time `json:"created_at"`
+	// when the source published the rate; freshness is checked against it, not created_at
+	AsOf time.Time `json:"as_of"`
+}
+
+type IdempotencyKey struct {
+	Username       string          `json:"username"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	RequestHash    string          `json:"request_hash"`
+	Response       json.RawMessage `json:"response"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type LedgerTransaction struct {
+	ID int64 `json:"id"`
+	// deposit, withdrawal, journal or fx_transfer
+	Type string `json:"type"`
+	// customer account funded or drawn down, source account of fx transfers, null for journals
+	AccountID sql.NullInt64 `json:"account_id"`
+	// must be positive, in the currency of account_id, null for journals
+	Amount    sql.NullInt64 `json:"amount"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type RevokedToken struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
 }
 
 type Session struct {
@@ -35,15 +127,48 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+	// id of the login session this refresh token was rotated from
+	FamilyID   uuid.UUID    `json:"family_id"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+	// id of the access token issued together with the refresh token
+	AccessTokenID uuid.NullUUID `json:"access_token_id"`
+}
+
+type TokenNotBefore struct {
+	Username string `json:"username"`
+	// tokens of the user issued before this time are revoked
+	NotBefore time.Time `json:"not_before"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
+	// must be positive, in the currency of the from account
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// amount credited to the to account in its currency, null when both accounts hold the same currency
+	ConvertedAmount sql.NullInt64 `json:"converted_amount"`
+	// quote that locked the rate of a transfer in another currency
+	QuoteID uuid.NullUUID `json:"quote_id"`
+}
+
+type TransferQuote struct {
+	ID            uuid.UUID `json:"id"`
+	Username      string    `json:"username"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	// amount debited from the source account, in its currency
+	Amount int64 `json:"amount"`
+	// amount credited to the destination account, in its currency
+	ConvertedAmount int64     `json:"converted_amount"`
+	ExchangeRateID  int64     `json:"exchange_rate_id"`
+	Rate            string    `json:"rate"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	// transfer that used the quote
+	LedgerTransactionID sql.NullInt64 `json:"ledger_transaction_id"`
+	UsedAt              sql.NullTime  `json:"used_at"`
+	CreatedAt           time.Time     `json:"created_at"`
 }
 
 type User struct {
@@ -53,4 +178,8 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// depositor for customers, banker and admin for bank staff
+	Role string `json:"role"`
+	// set while the user is locked out by an admin
+	DisabledAt sql.NullTime `json:"disabled_at"`
 }
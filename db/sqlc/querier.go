@@ -6,29 +6,93 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	ConsumeSession(ctx context.Context, id uuid.UUID) (Session, error)
+	CountActiveSessions(ctx context.Context, username string) (int64, error)
+	CountChainedEntries(ctx context.Context, accountID int64) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountIfNotExists(ctx context.Context, arg CreateAccountIfNotExistsParams) error
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateChainedEntry(ctx context.Context, arg CreateChainedEntryParams) (Entry, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateExchangeRate(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error)
+	// amount is debited in the currency of the from account, converted_amount
+	// credited in the currency of the to account at the rate of the quote
+	CreateFXTransfer(ctx context.Context, arg CreateFXTransferParams) (Transfer, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateLedgerTransaction(ctx context.Context, arg CreateLedgerTransactionParams) (LedgerTransaction, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferQuote(ctx context.Context, arg CreateTransferQuoteParams) (TransferQuote, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	// a user that is already disabled keeps its original disabled_at
+	DisableUser(ctx context.Context, arg DisableUserParams) (User, error)
+	EnableUser(ctx context.Context, username string) (User, error)
+	FilterTransfers(ctx context.Context, arg FilterTransfersParams) ([]Transfer, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetEntryChainCutoff(ctx context.Context) (EntryChainCutoff, error)
+	GetEntryChainHead(ctx context.Context, accountID int64) (EntryChainHead, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	// the caller must hold the lock on the account so the chain can't fork
+	GetLastEntryHash(ctx context.Context, accountID int64) ([]byte, error)
+	GetLatestExchangeRate(ctx context.Context, arg GetLatestExchangeRateParams) (ExchangeRate, error)
+	GetLedgerTransaction(ctx context.Context, id int64) (LedgerTransaction, error)
+	GetOwnerAccount(ctx context.Context, arg GetOwnerAccountParams) (Account, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetTokenNotBefore(ctx context.Context, username string) (time.Time, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferQuoteForUpdate(ctx context.Context, id uuid.UUID) (TransferQuote, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	IsTokenRevoked(ctx context.Context, id uuid.UUID) (bool, error)
+	ListAccountIDs(ctx context.Context, arg ListAccountIDsParams) ([]int64, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	// newest first; the keyset continues from the last event of the previous page
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error)
+	ListEntryChain(ctx context.Context, arg ListEntryChainParams) ([]Entry, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListSessions(ctx context.Context, username string) ([]Session, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	// the entries of a ledger transaction must sum to zero in every currency; a
+	// deposit or withdrawal must also have exactly one entry on the customer
+	// account for the signed amount, balanced by one on the system account
+	ListUnbalancedLedgerTransactions(ctx context.Context) ([]ListUnbalancedLedgerTransactionsRow, error)
+	// a transfer must have exactly one debit entry on the from account for its
+	// amount and one credit entry on the to account for its converted amount,
+	// which is the same amount when both accounts hold the same currency
+	ListUnmatchedTransfers(ctx context.Context) ([]ListUnmatchedTransfersRow, error)
+	// search matches part of the username or email, case-insensitively; its
+	// \, % and _ are escaped so they match themselves
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	LockIdempotencyKey(ctx context.Context, arg LockIdempotencyKeyParams) error
+	RevokeAllSessions(ctx context.Context, username string) (int64, error)
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error)
+	// the id of a session is the jti of its refresh token, so it is revoked along
+	// with the access token
+	RevokeSessionFamilyTokens(ctx context.Context, familyID uuid.UUID) error
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	SetTokenNotBefore(ctx context.Context, arg SetTokenNotBeforeParams) error
+	SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error)
 	UpdateAccountBalance(ctx context.Context, arg UpdateAccountBalanceParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	// the caller must hold the lock on the account
+	UpsertEntryChainHead(ctx context.Context, arg UpsertEntryChainHeadParams) error
+	UseTransferQuote(ctx context.Context, arg UseTransferQuoteParams) (TransferQuote, error)
 }
 
 var _ Querier = (*Queries)(nil)
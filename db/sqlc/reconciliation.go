@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ReconciliationReport lists where account balances, entries, transfers and
+// ledger transactions disagree with each other
+type ReconciliationReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// BalanceMismatches are accounts whose balance is not the sum of their entries
+	BalanceMismatches []ListBalanceMismatchesRow `json:"balance_mismatches"`
+	// UnmatchedTransfers are transfers without exactly two matching entries
+	UnmatchedTransfers []ListUnmatchedTransfersRow `json:"unmatched_transfers"`
+	// UnbalancedLedgerTransactions are deposits and withdrawals whose entries
+	// don't match the transaction or don't sum to zero
+	UnbalancedLedgerTransactions []ListUnbalancedLedgerTransactionsRow `json:"unbalanced_ledger_transactions"`
+}
+
+// Clean reports whether no discrepancy was found
+func (report ReconciliationReport) Clean() bool {
+	return len(report.BalanceMismatches) == 0 &&
+		len(report.UnmatchedTransfers) == 0 &&
+		len(report.UnbalancedLedgerTransactions) == 0
+}
+
+// Reconcile checks that the balance of every account equals the sum of its
+// entries, that every transfer has exactly two matching entries and that
+// every deposit and withdrawal is balanced. All checks read the same
+// snapshot, so transactions committed in between can't show up as
+// discrepancies.
+func (store *SQLStore) Reconcile(ctx context.Context) (ReconciliationReport, error) {
+	report := ReconciliationReport{CheckedAt: time.Now()}
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.exeTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+		report.BalanceMismatches, err = q.ListBalanceMismatches(ctx)
+		if err != nil {
+			return err
+		}
+		report.UnmatchedTransfers, err = q.ListUnmatchedTransfers(ctx)
+		if err != nil {
+			return err
+		}
+		report.UnbalancedLedgerTransactions, err = q.ListUnbalancedLedgerTransactions(ctx)
+		return err
+	})
+	return report, err
+}
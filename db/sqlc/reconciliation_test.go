@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	// 余额从0开始，才能和账户的分录对得上
+	account1 := createFundedAccount(t, "USD", 0)
+	account2 := createFundedAccount(t, "USD", 0)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	report, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, balanceMismatchIDs(report), account1.ID)
+	require.NotContains(t, balanceMismatchIDs(report), account2.ID)
+	require.NotContains(t, unmatchedTransferIDs(report), result.Transfer.ID)
+
+	// 直接改余额会和分录对不上
+	_, err = testQueries.UpdateAccountBalance(context.Background(), UpdateAccountBalanceParams{
+		ID:      account1.ID,
+		Balance: 100,
+	})
+	require.NoError(t, err)
+
+	// 没有分录的转账
+	transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	report, err = store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.False(t, report.Clean())
+	require.Contains(t, balanceMismatchIDs(report), account1.ID)
+	require.NotContains(t, balanceMismatchIDs(report), account2.ID)
+	require.Contains(t, unmatchedTransferIDs(report), transfer.ID)
+	require.NotContains(t, unmatchedTransferIDs(report), result.Transfer.ID)
+}
+
+func balanceMismatchIDs(report ReconciliationReport) []int64 {
+	ids := make([]int64, 0, len(report.BalanceMismatches))
+	for _, mismatch := range report.BalanceMismatches {
+		ids = append(ids, mismatch.AccountID)
+	}
+	return ids
+}
+
+func unmatchedTransferIDs(report ReconciliationReport) []int64 {
+	ids := make([]int64, 0, len(report.UnmatchedTransfers))
+	for _, transfer := range report.UnmatchedTransfers {
+		ids = append(ids, transfer.TransferID)
+	}
+	return ids
+}
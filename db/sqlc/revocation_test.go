@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func TestRevokeToken(t *testing.T) {
+	user, err := createRandomUser(t)
+	require.NoError(t, err)
+
+	arg := RevokeTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), arg.ID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	err = testQueries.RevokeToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	// 重复吊销同一个token不报错
+	err = testQueries.RevokeToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), arg.ID)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestSetTokenNotBefore(t *testing.T) {
+	user, err := createRandomUser(t)
+	require.NoError(t, err)
+
+	// 没有撤销记录时取密码修改时间
+	got, err := testQueries.GetTokenNotBefore(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, user.PasswordChangedAt, got, time.Second)
+
+	_, err = testQueries.GetTokenNotBefore(context.Background(), utils.RandomOwnerName())
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	notBefore := time.Now()
+	err = testQueries.SetTokenNotBefore(context.Background(), SetTokenNotBeforeParams{
+		Username:  user.Username,
+		NotBefore: notBefore,
+	})
+	require.NoError(t, err)
+
+	// 较早的时间不会覆盖已有的值
+	err = testQueries.SetTokenNotBefore(context.Background(), SetTokenNotBeforeParams{
+		Username:  user.Username,
+		NotBefore: notBefore.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	got, err = testQueries.GetTokenNotBefore(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, notBefore, got, time.Second)
+}
+
+func TestGetTokenNotBeforePasswordChanged(t *testing.T) {
+	user, err := createRandomUser(t)
+	require.NoError(t, err)
+
+	err = testQueries.SetTokenNotBefore(context.Background(), SetTokenNotBeforeParams{
+		Username:  user.Username,
+		NotBefore: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	changedAt := time.Now()
+	_, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Username:          user.Username,
+		PasswordChangedAt: sql.NullTime{Time: changedAt, Valid: true},
+	})
+	require.NoError(t, err)
+
+	got, err := testQueries.GetTokenNotBefore(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, changedAt, got, time.Second)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type RevokeSessionTxParams struct {
+	RevokeSessionParams
+	Audit *AuditParams `json:"-"`
+}
+
+// RevokeSessionTx blocks the family of a session owned by the user and
+// revokes every access token issued for it. It returns the number of
+// sessions blocked, which is zero if the user has no such session.
+func (store *SQLStore) RevokeSessionTx(ctx context.Context, arg RevokeSessionTxParams) (int64, error) {
+	var revoked int64
+	err := store.exeTx(ctx, func(q *Queries) error {
+		var err error
+		revoked, err = q.RevokeSession(ctx, arg.RevokeSessionParams)
+		if err != nil || revoked == 0 {
+			return err
+		}
+		session, err := q.GetSession(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		err = q.RevokeSessionFamilyTokens(ctx, session.FamilyID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditSessionRevoke, AuditTargetSession, session.ID.String(), nil, map[string]any{
+			"family_id":        session.FamilyID,
+			"revoked_sessions": revoked,
+		})
+	})
+	return revoked, err
+}
+
+type RevokeAllSessionsTxParams struct {
+	Username string `json:"username"`
+	// NotBefore revokes every token of the user issued before it
+	NotBefore time.Time    `json:"not_before"`
+	Audit     *AuditParams `json:"-"`
+}
+
+// RevokeAllSessionsTx blocks all sessions of the user and revokes every
+// token issued to the user before NotBefore
+func (store *SQLStore) RevokeAllSessionsTx(ctx context.Context, arg RevokeAllSessionsTxParams) error {
+	return store.exeTx(ctx, func(q *Queries) error {
+		revoked, err := q.RevokeAllSessions(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		err = q.SetTokenNotBefore(ctx, SetTokenNotBeforeParams{
+			Username:  arg.Username,
+			NotBefore: arg.NotBefore,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditSessionsRevoke, AuditTargetUser, arg.Username, nil, map[string]any{
+			"revoked_sessions": revoked,
+			"not_before":       arg.NotBefore,
+		})
+	})
+}
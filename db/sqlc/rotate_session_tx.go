@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type RotateSessionTxParams struct {
+	// SessionID is the session of the refresh token being exchanged
+	SessionID uuid.UUID `json:"session_id"`
+	// NewSession is the session of the newly issued refresh token; its
+	// FamilyID is inherited from the rotated session
+	NewSession CreateSessionParams `json:"new_session"`
+	Audit      *AuditParams        `json:"-"`
+}
+
+// RotateSessionTx consumes the session of a refresh token and creates its
+// successor in the same family. If the session was already consumed, the
+// refresh token is being replayed: every session of the family is blocked,
+// their access tokens are revoked and ErrRefreshTokenReused is returned.
+// Both the rotation and the blocking are recorded in the audit log.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error) {
+	var session Session
+	reused := false
+	err := store.exeTx(ctx, func(q *Queries) error {
+		consumed, err := q.ConsumeSession(ctx, arg.SessionID)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			//会话已经被消费过，说明刷新token被重复使用，封禁整个会话族
+			//封禁需要提交，所以这里不返回错误，等事务提交后再返回
+			old, err := q.GetSession(ctx, arg.SessionID)
+			if err != nil {
+				return err
+			}
+			reused = true
+			if err := q.BlockSessionFamily(ctx, old.FamilyID); err != nil {
+				return err
+			}
+			if err := q.RevokeSessionFamilyTokens(ctx, old.FamilyID); err != nil {
+				return err
+			}
+			return recordAudit(ctx, q, arg.Audit, AuditSessionReuse, AuditTargetSession, old.ID.String(), auditSession(old), map[string]any{
+				"family_id": old.FamilyID,
+			})
+		}
+		if consumed.IsBlocked {
+			return ErrSessionBlocked
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = consumed.FamilyID
+		session, err = q.CreateSession(ctx, newSession)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditSessionRotate, AuditTargetSession, session.ID.String(), auditSession(consumed), auditSession(session))
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	if reused {
+		return Session{}, ErrRefreshTokenReused
+	}
+	return session, nil
+}
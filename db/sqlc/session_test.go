@@ -0,0 +1,230 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func randomSessionParams(username string, familyID uuid.UUID) CreateSessionParams {
+	id := uuid.New()
+	if familyID == uuid.Nil {
+		familyID = id
+	}
+	return CreateSessionParams{
+		ID:           id,
+		Username:     username,
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    utils.RandomString(10),
+		ClientIp:     utils.RandomString(10),
+		IsBlocked:    false,
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     familyID,
+		AccessTokenID: uuid.NullUUID{
+			UUID:  uuid.New(),
+			Valid: true,
+		},
+	}
+}
+
+func createRandomSession(t *testing.T) Session {
+	user, err := createRandomUser(t)
+	require.NoError(t, err)
+
+	arg := randomSessionParams(user.Username, uuid.Nil)
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.ID, session.FamilyID)
+	require.False(t, session.ConsumedAt.Valid)
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	createRandomSession(t)
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+
+	arg := RotateSessionTxParams{
+		SessionID:  session.ID,
+		NewSession: randomSessionParams(session.Username, uuid.Nil),
+	}
+	newSession, err := store.RotateSessionTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewSession.ID, newSession.ID)
+	require.Equal(t, session.FamilyID, newSession.FamilyID)
+	require.False(t, newSession.ConsumedAt.Valid)
+
+	oldSession, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, oldSession.ConsumedAt.Valid)
+	require.False(t, oldSession.IsBlocked)
+}
+
+func TestRotateSessionTxReuse(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+
+	newSession, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  session.ID,
+		NewSession: randomSessionParams(session.Username, uuid.Nil),
+	})
+	require.NoError(t, err)
+
+	// 再次使用已经轮换过的刷新token
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  session.ID,
+		NewSession: randomSessionParams(session.Username, uuid.Nil),
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	for _, id := range []uuid.UUID{session.ID, newSession.ID} {
+		blocked, err := testQueries.GetSession(context.Background(), id)
+		require.NoError(t, err)
+		require.True(t, blocked.IsBlocked)
+
+		for _, tokenID := range []uuid.UUID{blocked.AccessTokenID.UUID, blocked.ID} {
+			revoked, err := testQueries.IsTokenRevoked(context.Background(), tokenID)
+			require.NoError(t, err)
+			require.True(t, revoked)
+		}
+	}
+
+	// 整个会话族被封禁后，最新的刷新token也不能再使用
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  newSession.ID,
+		NewSession: randomSessionParams(session.Username, uuid.Nil),
+	})
+	require.ErrorIs(t, err, ErrSessionBlocked)
+}
+
+func TestListSessions(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session1 := createRandomSession(t)
+	session2, err := testQueries.CreateSession(context.Background(), randomSessionParams(session1.Username, uuid.Nil))
+	require.NoError(t, err)
+
+	// 轮换后只有最新的会话是活跃的
+	rotated, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		SessionID:  session1.ID,
+		NewSession: randomSessionParams(session1.Username, uuid.Nil),
+	})
+	require.NoError(t, err)
+
+	expired := randomSessionParams(session1.Username, uuid.Nil)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = testQueries.CreateSession(context.Background(), expired)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListSessions(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, rotated.ID, sessions[0].ID)
+	require.Equal(t, session2.ID, sessions[1].ID)
+}
+
+func TestCountActiveSessions(t *testing.T) {
+	session := createRandomSession(t)
+	_, err := testQueries.CreateSession(context.Background(), randomSessionParams(session.Username, uuid.Nil))
+	require.NoError(t, err)
+
+	count, err := testQueries.CountActiveSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
+
+func TestRevokeSession(t *testing.T) {
+	session1 := createRandomSession(t)
+	session2, err := testQueries.CreateSession(context.Background(), randomSessionParams(session1.Username, uuid.Nil))
+	require.NoError(t, err)
+
+	revoked, err := testQueries.RevokeSession(context.Background(), RevokeSessionParams{
+		ID:       session1.ID,
+		Username: utils.RandomOwnerName(),
+	})
+	require.NoError(t, err)
+	require.Zero(t, revoked)
+
+	revoked, err = testQueries.RevokeSession(context.Background(), RevokeSessionParams{
+		ID:       session1.ID,
+		Username: session1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	sessions, err := testQueries.ListSessions(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session2.ID, sessions[0].ID)
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	session := createRandomSession(t)
+	_, err := testQueries.CreateSession(context.Background(), randomSessionParams(session.Username, uuid.Nil))
+	require.NoError(t, err)
+
+	revoked, err := testQueries.RevokeAllSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), revoked)
+
+	sessions, err := testQueries.ListSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestRevokeSessionTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+	other, err := testQueries.CreateSession(context.Background(), randomSessionParams(session.Username, uuid.Nil))
+	require.NoError(t, err)
+
+	revoked, err := store.RevokeSessionTx(context.Background(), RevokeSessionTxParams{
+		RevokeSessionParams: RevokeSessionParams{
+			ID:       session.ID,
+			Username: session.Username,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	isRevoked, err := testQueries.IsTokenRevoked(context.Background(), session.AccessTokenID.UUID)
+	require.NoError(t, err)
+	require.True(t, isRevoked)
+
+	// 会话的id就是刷新token的jti，同样被撤销
+	isRevoked, err = testQueries.IsTokenRevoked(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, isRevoked)
+
+	// 其他会话族的访问token不受影响
+	isRevoked, err = testQueries.IsTokenRevoked(context.Background(), other.AccessTokenID.UUID)
+	require.NoError(t, err)
+	require.False(t, isRevoked)
+}
+
+func TestRevokeAllSessionsTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+
+	notBefore := time.Now()
+	err := store.RevokeAllSessionsTx(context.Background(), RevokeAllSessionsTxParams{
+		Username:  session.Username,
+		NotBefore: notBefore,
+	})
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	got, err := testQueries.GetTokenNotBefore(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, notBefore, got, time.Second)
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type GetStatementParams struct {
+	AccountID int64     `json:"account_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type StatementLine struct {
+	ListStatementEntriesRow
+	RunningBalance int64 `json:"running_balance"`
+}
+
+type Statement struct {
+	Account        Account         `json:"account"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// GetStatement lists the account's entries in [StartTime, EndTime) with the
+// balance after each one. The opening balance is worked back from the current
+// balance, so accounts funded without entries still add up.
+func (store *SQLStore) GetStatement(ctx context.Context, arg GetStatementParams) (Statement, error) {
+	var statement Statement
+	//可重复读的只读事务，保证余额和流水来自同一个快照
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.exeTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+		statement.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		sinceStart, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.StartTime,
+		})
+		if err != nil {
+			return err
+		}
+
+		entries, err := q.ListStatementEntries(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			StartTime: arg.StartTime,
+			EndTime:   arg.EndTime,
+		})
+		if err != nil {
+			return err
+		}
+
+		statement.OpeningBalance = statement.Account.Balance - sinceStart
+		balance := statement.OpeningBalance
+		statement.Lines = make([]StatementLine, 0, len(entries))
+		for _, entry := range entries {
+			balance += entry.Amount
+			statement.Lines = append(statement.Lines, StatementLine{
+				ListStatementEntriesRow: entry,
+				RunningBalance:          balance,
+			})
+		}
+		statement.ClosingBalance = balance
+		return nil
+	})
+	return statement, err
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatement(t *testing.T) {
+	testStore := NewStore(testDB, testLedgerHashKey)
+
+	account1 := createFundedAccount(t, "USD", 1000)
+	account2 := createFundedAccount(t, "USD", 1000)
+
+	// an entry made before the statement window only moves the opening balance
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	startTime := time.Now()
+
+	var transfers []TransferTxResult
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 50},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 20},
+	} {
+		result, err := testStore.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+		transfers = append(transfers, result)
+	}
+
+	statement, err := testStore.GetStatement(context.Background(), GetStatementParams{
+		AccountID: account1.ID,
+		StartTime: startTime,
+		EndTime:   time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account1.ID, statement.Account.ID)
+	require.Equal(t, int64(900), statement.OpeningBalance)
+	require.Equal(t, int64(900), statement.ClosingBalance)
+	require.Equal(t, statement.Account.Balance, statement.ClosingBalance)
+	require.Len(t, statement.Lines, 3)
+
+	runningBalances := []int64{870, 920, 900}
+	for i, line := range statement.Lines {
+		require.Equal(t, account1.ID, line.AccountID)
+		require.Equal(t, account2.ID, line.CounterpartyAccountID)
+		require.True(t, line.TransferID.Valid)
+		require.Equal(t, transfers[i].Transfer.ID, line.TransferID.Int64)
+		require.Equal(t, runningBalances[i], line.RunningBalance)
+	}
+}
@@ -9,11 +9,34 @@ import (
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
+	GetStatement(ctx context.Context, arg GetStatementParams) (Statement, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error)
+	CreateSessionTx(ctx context.Context, arg CreateSessionTxParams) (Session, error)
+	RevokeSessionTx(ctx context.Context, arg RevokeSessionTxParams) (int64, error)
+	RevokeAllSessionsTx(ctx context.Context, arg RevokeAllSessionsTxParams) error
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (User, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (User, error)
+	DisableUserTx(ctx context.Context, arg DisableUserTxParams) (User, error)
+	EnableUserTx(ctx context.Context, arg EnableUserTxParams) (User, error)
+	VerifyEntryChain(ctx context.Context, accountID int64) (*ChainBreak, error)
+	VerifyLedger(ctx context.Context) ([]ChainBreak, error)
+	AnchorEntryChain(ctx context.Context, accountID int64) (*ChainBreak, error)
+	AnchorLedger(ctx context.Context) ([]ChainBreak, error)
+	Reconcile(ctx context.Context) (ReconciliationReport, error)
+	DepositTx(ctx context.Context, arg LedgerTxParams) (LedgerTxResult, error)
+	WithdrawTx(ctx context.Context, arg LedgerTxParams) (LedgerTxResult, error)
+	PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error)
+	CreateExchangeRatesTx(ctx context.Context, args []CreateExchangeRateParams) ([]ExchangeRate, error)
+	CreateTransferQuoteTx(ctx context.Context, arg CreateTransferQuoteTxParams) (TransferQuote, error)
+	FXTransferTx(ctx context.Context, arg FXTransferTxParams) (FXTransferTxResult, error)
 }
 
 type SQLStore struct {
 	*Queries
 	db *sql.DB
+	// ledgerKey keys the hash chain of the entries and the heads that anchor it
+	ledgerKey []byte
 }
 
 type TransferTxResult struct {
@@ -28,17 +51,27 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// Idempotency is optional; a replayed key returns the original result
+	Idempotency *IdempotencyParams `json:"-"`
+	Audit       *AuditParams       `json:"-"`
 }
 
-func NewStore(db *sql.DB) Store {
+// NewStore creates a store whose entry hash chain is keyed with ledgerKey,
+// LEDGER_HASH_KEY of the config
+func NewStore(db *sql.DB, ledgerKey string) Store {
 	return &SQLStore{
-		Queries: New(db),
-		db:      db,
+		Queries:   New(db),
+		db:        db,
+		ledgerKey: []byte(ledgerKey),
 	}
 }
 
 func (store *SQLStore) exeTx(ctx context.Context, fn func(q *Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil) //创建一个数据库事务变量，得到指针
+	return store.exeTxWithOptions(ctx, nil, fn)
+}
+
+func (store *SQLStore) exeTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(q *Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts) //创建一个数据库事务变量，得到指针
 	if err != nil {
 		return err
 	}
@@ -56,25 +89,50 @@ func (store *SQLStore) exeTx(ctx context.Context, fn func(q *Queries) error) err
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
-	err := store.exeTx(ctx, func(q *Queries) error {
+	err := store.idempotentTx(ctx, arg.Idempotency, "transfer", arg, &result, func(q *Queries) error {
 		var err error
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		//按id从小到大锁定两个账户，避免死锁，再检查转出账户余额是否足够
+		var fromAccount, toAccount Account
+		if arg.FromAccountID < arg.ToAccountID {
+			fromAccount, toAccount, err = lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		} else {
+			toAccount, fromAccount, err = lockAccounts(ctx, q, arg.ToAccountID, arg.FromAccountID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if IsBankAccount(fromAccount.Owner) || IsBankAccount(toAccount.Owner) {
+			return ErrSystemAccount
+		}
+
+		if fromAccount.Balance-arg.Amount < -fromAccount.OverdraftLimit {
+			return ErrInsufficientFunds
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+		})
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		result.FromEntry, err = store.appendEntry(ctx, q, CreateChainedEntryParams{
+			AccountID:  arg.FromAccountID,
+			Amount:     -arg.Amount,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
 		})
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		result.ToEntry, err = store.appendEntry(ctx, q, CreateChainedEntryParams{
+			AccountID:  arg.ToAccountID,
+			Amount:     arg.Amount,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
 		})
 		if err != nil {
 			return err
@@ -87,8 +145,11 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 		} else {
 			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
 		}
+		if err != nil {
+			return err
+		}
 
-		return err
+		return recordAudit(ctx, q, arg.Audit, AuditTransferCreate, AuditTargetTransfer, auditID(result.Transfer.ID), nil, result.Transfer)
 
 	})
 	if err != nil {
@@ -97,6 +158,15 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	return result, err
 }
 
+func lockAccounts(ctx context.Context, q *Queries, account1ID int64, account2ID int64) (account1 Account, account2 Account, err error) {
+	account1, err = q.GetAccountForUpdate(ctx, account1ID)
+	if err != nil {
+		return
+	}
+	account2, err = q.GetAccountForUpdate(ctx, account2ID)
+	return
+}
+
 func addMoney(ctx context.Context, q *Queries,
 	account1ID int64, amount1 int64,
 	account2ID int64, amount2 int64) (updateAccount1 Account, updateAccount2 Account, err error) {
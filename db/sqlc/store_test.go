@@ -9,10 +9,10 @@ import (
 )
 
 func TestTransTx(t *testing.T) {
-	testStore := NewStore(testDB)
+	testStore := NewStore(testDB, testLedgerHashKey)
 
-	account1, _ := createRandomAccount(t)
-	account2, _ := createRandomAccount(t)
+	account1 := createFundedAccount(t, "USD", 1000)
+	account2 := createFundedAccount(t, "USD", 1000)
 	amount := int64(10)
 	n := 5
 	fmt.Printf("tx: account1 balance %d account2 balance %d\n", account1.Balance, account2.Balance)
@@ -55,6 +55,7 @@ func TestTransTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotEmpty(t, fromEntry.CreatedAt)
 		require.NotEmpty(t, fromEntry.ID)
 
@@ -62,6 +63,7 @@ func TestTransTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		require.NotEmpty(t, toEntry.CreatedAt)
 		require.NotEmpty(t, toEntry.ID)
 
@@ -98,10 +100,10 @@ func TestTransTx(t *testing.T) {
 
 }
 func TestTransTxDeadLock(t *testing.T) {
-	testStore := NewStore(testDB)
+	testStore := NewStore(testDB, testLedgerHashKey)
 
-	account1, _ := createRandomAccount(t)
-	account2, _ := createRandomAccount(t)
+	account1 := createFundedAccount(t, "USD", 1000)
+	account2 := createFundedAccount(t, "USD", 1000)
 	amount := int64(10)
 	n := 10
 	fmt.Printf("tx: account1 balance %d account2 balance %d\n", account1.Balance, account2.Balance)
@@ -150,3 +152,55 @@ func TestTransTxDeadLock(t *testing.T) {
 	require.Equal(t, account2.Balance, updateToAccount.Balance)
 
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	testStore := NewStore(testDB, testLedgerHashKey)
+
+	account1 := createFundedAccount(t, "USD", 100)
+	account2 := createFundedAccount(t, "USD", 100)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        101,
+	})
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updateFromAccount, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updateFromAccount.Balance)
+
+	updateToAccount, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updateToAccount.Balance)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	testStore := NewStore(testDB, testLedgerHashKey)
+
+	account1 := createFundedAccount(t, "USD", 100)
+	account2 := createFundedAccount(t, "USD", 100)
+
+	account1, err := testStore.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 50,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        150,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-50), result.FromAccount.Balance)
+	require.Equal(t, int64(250), result.ToAccount.Balance)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
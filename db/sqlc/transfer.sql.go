@@ -7,11 +7,50 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
+const createFXTransfer = `-- name: CreateFXTransfer :one
+INSERT INTO transfers(from_account_id,to_account_id,amount,converted_amount,quote_id)
+VALUES($1,$2,$3,$4,$5)RETURNING id, from_account_id, to_account_id, amount, created_at, converted_amount, quote_id
+`
+
+type CreateFXTransferParams struct {
+	FromAccountID   int64         `json:"from_account_id"`
+	ToAccountID     int64         `json:"to_account_id"`
+	Amount          int64         `json:"amount"`
+	ConvertedAmount sql.NullInt64 `json:"converted_amount"`
+	QuoteID         uuid.NullUUID `json:"quote_id"`
+}
+
+// amount is debited in the currency of the from account, converted_amount
+// credited in the currency of the to account at the rate of the quote
+func (q *Queries) CreateFXTransfer(ctx context.Context, arg CreateFXTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createFXTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ConvertedAmount,
+		arg.QuoteID,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ConvertedAmount,
+		&i.QuoteID,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers(from_account_id,to_account_id,amount)
-VALUES($1,$2,$3)RETURNING id, from_account_id, to_account_id, amount, created_at
+VALUES($1,$2,$3)RETURNING id, from_account_id, to_account_id, amount, created_at, converted_amount, quote_id
 `
 
 type CreateTransferParams struct {
@@ -29,12 +68,101 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ConvertedAmount,
+		&i.QuoteID,
 	)
 	return i, err
 }
 
+const filterTransfers = `-- name: FilterTransfers :many
+WITH owned_accounts AS (
+  SELECT id FROM accounts
+  WHERE owner = $1
+    AND ($2::bigint IS NULL OR id = $2)
+)
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.converted_amount, t.quote_id FROM transfers t
+WHERE (
+    ($3::boolean
+      AND t.from_account_id IN (SELECT id FROM owned_accounts)
+      AND ($4::bigint IS NULL OR t.to_account_id = $4))
+    OR ($5::boolean
+      AND t.to_account_id IN (SELECT id FROM owned_accounts)
+      AND ($4::bigint IS NULL OR t.from_account_id = $4))
+  )
+  AND ($6::bigint IS NULL OR t.amount >= $6)
+  AND ($7::bigint IS NULL OR t.amount <= $7)
+  AND ($8::timestamptz IS NULL OR t.created_at >= $8)
+  AND ($9::timestamptz IS NULL OR t.created_at < $9)
+  AND ($10::timestamptz IS NULL
+    OR (t.created_at, t.id) > ($10::timestamptz, $11::bigint))
+ORDER BY t.created_at, t.id
+LIMIT $12
+OFFSET $13
+`
+
+type FilterTransfersParams struct {
+	Owner                 string        `json:"owner"`
+	AccountID             sql.NullInt64 `json:"account_id"`
+	IncludeOutgoing       bool          `json:"include_outgoing"`
+	CounterpartyAccountID sql.NullInt64 `json:"counterparty_account_id"`
+	IncludeIncoming       bool          `json:"include_incoming"`
+	MinAmount             sql.NullInt64 `json:"min_amount"`
+	MaxAmount             sql.NullInt64 `json:"max_amount"`
+	StartTime             sql.NullTime  `json:"start_time"`
+	EndTime               sql.NullTime  `json:"end_time"`
+	AfterCreatedAt        sql.NullTime  `json:"after_created_at"`
+	AfterID               int64         `json:"after_id"`
+	Limit                 int32         `json:"limit"`
+	Offset                int32         `json:"offset"`
+}
+
+func (q *Queries) FilterTransfers(ctx context.Context, arg FilterTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, filterTransfers,
+		arg.Owner,
+		arg.AccountID,
+		arg.IncludeOutgoing,
+		arg.CounterpartyAccountID,
+		arg.IncludeIncoming,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartTime,
+		arg.EndTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ConvertedAmount,
+			&i.QuoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, converted_amount, quote_id FROM transfers
 WHERE id=$1
 `
 
@@ -47,12 +175,14 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ConvertedAmount,
+		&i.QuoteID,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, converted_amount, quote_id FROM transfers
 WHERE from_account_id=$1 OR to_account_id=$2
 ORDER BY id
 LIMIT $3
@@ -86,6 +216,8 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ConvertedAmount,
+			&i.QuoteID,
 		); err != nil {
 			return nil, err
 		}
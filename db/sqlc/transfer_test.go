@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -99,3 +100,123 @@ func TestListTransfers(t *testing.T) {
 	}
 
 }
+
+func TestFilterTransfers(t *testing.T) {
+	account, _ := createRandomAccount(t)
+	counterparty1, _ := createRandomAccount(t)
+	counterparty2, _ := createRandomAccount(t)
+
+	createTransfer := func(from, to Account, amount int64) Transfer {
+		transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		return transfer
+	}
+	out1 := createTransfer(account, counterparty1, 100)
+	in1 := createTransfer(counterparty1, account, 200)
+	out2 := createTransfer(account, counterparty2, 300)
+	in2 := createTransfer(counterparty2, account, 400)
+
+	testCases := []struct {
+		name     string
+		arg      FilterTransfersParams
+		expected []Transfer
+	}{
+		{
+			name: "Both",
+			arg: FilterTransfersParams{
+				IncludeOutgoing: true,
+				IncludeIncoming: true,
+			},
+			expected: []Transfer{out1, in1, out2, in2},
+		},
+		{
+			name: "Outgoing",
+			arg: FilterTransfersParams{
+				IncludeOutgoing: true,
+			},
+			expected: []Transfer{out1, out2},
+		},
+		{
+			name: "Incoming",
+			arg: FilterTransfersParams{
+				IncludeIncoming: true,
+			},
+			expected: []Transfer{in1, in2},
+		},
+		{
+			name: "Counterparty",
+			arg: FilterTransfersParams{
+				IncludeOutgoing:       true,
+				IncludeIncoming:       true,
+				CounterpartyAccountID: sql.NullInt64{Int64: counterparty2.ID, Valid: true},
+			},
+			expected: []Transfer{out2, in2},
+		},
+		{
+			name: "AmountRange",
+			arg: FilterTransfersParams{
+				IncludeOutgoing: true,
+				IncludeIncoming: true,
+				MinAmount:       sql.NullInt64{Int64: 200, Valid: true},
+				MaxAmount:       sql.NullInt64{Int64: 300, Valid: true},
+			},
+			expected: []Transfer{in1, out2},
+		},
+		{
+			name: "TimeRange",
+			arg: FilterTransfersParams{
+				IncludeOutgoing: true,
+				IncludeIncoming: true,
+				StartTime:       sql.NullTime{Time: in1.CreatedAt, Valid: true},
+				EndTime:         sql.NullTime{Time: in2.CreatedAt, Valid: true},
+			},
+			expected: []Transfer{in1, out2},
+		},
+		{
+			name: "Account",
+			arg: FilterTransfersParams{
+				AccountID:       sql.NullInt64{Int64: account.ID, Valid: true},
+				IncludeOutgoing: true,
+				IncludeIncoming: true,
+			},
+			expected: []Transfer{out1, in1, out2, in2},
+		},
+		{
+			name: "AfterCursor",
+			arg: FilterTransfersParams{
+				IncludeOutgoing: true,
+				IncludeIncoming: true,
+				AfterCreatedAt:  sql.NullTime{Time: in1.CreatedAt, Valid: true},
+				AfterID:         in1.ID,
+			},
+			expected: []Transfer{out2, in2},
+		},
+		{
+			name: "OtherOwner",
+			arg: FilterTransfersParams{
+				Owner:           counterparty1.Owner,
+				IncludeOutgoing: true,
+				IncludeIncoming: true,
+			},
+			expected: []Transfer{out1, in1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			arg := tc.arg
+			if arg.Owner == "" {
+				arg.Owner = account.Owner
+			}
+			arg.Limit = 10
+
+			transfers, err := testQueries.FilterTransfers(context.Background(), arg)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, transfers)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package db
+
+import "context"
+
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	Audit *AuditParams `json:"-"`
+}
+
+// UpdateUserTx updates the user and, when the password changes, blocks every
+// session of the user so that refresh tokens issued before the change stop
+// working. Access tokens are rejected by comparing their issue time against
+// password_changed_at.
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (User, error) {
+	var user User
+	err := store.exeTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+		if arg.PasswordChangedAt.Valid {
+			_, err = q.RevokeAllSessions(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, q, arg.Audit, AuditUserUpdate, AuditTargetUser, user.Username, auditUser(before), auditUser(user))
+	})
+	return user, err
+}
@@ -12,7 +12,7 @@ import (
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username,hashed_password,full_name,email)
-VALUES($1,$2,$3,$4) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+VALUES($1,$2,$3,$4) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, disabled_at
 `
 
 type CreateUserParams struct {
@@ -37,12 +37,66 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const disableUser = `-- name: DisableUser :one
+UPDATE users
+SET disabled_at = COALESCE(disabled_at, $1)
+WHERE username = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, disabled_at
+`
+
+type DisableUserParams struct {
+	DisabledAt sql.NullTime `json:"disabled_at"`
+	Username   string       `json:"username"`
+}
+
+// a user that is already disabled keeps its original disabled_at
+func (q *Queries) DisableUser(ctx context.Context, arg DisableUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, disableUser, arg.DisabledAt, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const enableUser = `-- name: EnableUser :one
+UPDATE users
+SET disabled_at = NULL
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, disabled_at
+`
+
+func (q *Queries) EnableUser(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableUser, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users WHERE username = $1 LIMIT 1
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, disabled_at FROM users WHERE username = $1 LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
@@ -55,10 +109,69 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
+const listUsers = `-- name: ListUsers :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, role, disabled_at FROM users
+WHERE ($1::text IS NULL
+    OR username ILIKE '%' || replace(replace(replace($1::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR email ILIKE '%' || replace(replace(replace($1::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($2::timestamptz IS NULL
+    OR (created_at, username) > ($2::timestamptz, $3::text))
+ORDER BY created_at, username
+LIMIT $4
+`
+
+type ListUsersParams struct {
+	Search         sql.NullString `json:"search"`
+	AfterCreatedAt sql.NullTime   `json:"after_created_at"`
+	AfterUsername  string         `json:"after_username"`
+	Limit          int32          `json:"limit"`
+}
+
+// search matches part of the username or email, case-insensitively; its
+// \, % and _ are escaped so they match themselves
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers,
+		arg.Search,
+		arg.AfterCreatedAt,
+		arg.AfterUsername,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET 
@@ -68,7 +181,7 @@ SET
     email = COALESCE($4,email)
 WHERE 
 username=$5
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, role, disabled_at
 `
 
 type UpdateUserParams struct {
@@ -95,6 +208,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.DisabledAt,
 	)
 	return i, err
 }
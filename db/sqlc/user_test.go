@@ -26,6 +26,8 @@ func createRandomUser(t *testing.T) (User, error) {
 	require.Equal(t, arg.Email, user.Email)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
+	require.Equal(t, "depositor", user.Role)
+	require.False(t, user.DisabledAt.Valid)
 
 	require.Zero(t, user.PasswordChangedAt)
 	require.NotEmpty(t, user.CreatedAt)
@@ -148,3 +150,153 @@ func TestUpdateUserAllFields(t *testing.T) {
 	require.Equal(t, newPassword, updatedUser.HashedPassword)
 
 }
+
+func TestUpdateUserTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+
+	// 只修改全名不影响会话
+	_, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: session.Username,
+			FullName: sql.NullString{
+				String: utils.RandomOwnerName(),
+				Valid:  true,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	changedAt := time.Now()
+	updatedUser, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: session.Username,
+			HashedPassword: sql.NullString{
+				String: utils.RandomString(10),
+				Valid:  true,
+			},
+			PasswordChangedAt: sql.NullTime{
+				Time:  changedAt,
+				Valid: true,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, changedAt, updatedUser.PasswordChangedAt, time.Second)
+
+	sessions, err = testQueries.ListSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestListUsers(t *testing.T) {
+	user1, _ := createRandomUser(t)
+	user2, _ := createRandomUser(t)
+
+	search := sql.NullString{String: user1.Username, Valid: true}
+	users, err := testQueries.ListUsers(context.Background(), ListUsersParams{
+		Search: search,
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, user1.Username, users[0].Username)
+
+	// 按(created_at, username)翻页
+	users, err = testQueries.ListUsers(context.Background(), ListUsersParams{
+		AfterCreatedAt: sql.NullTime{Time: user1.CreatedAt, Valid: true},
+		AfterUsername:  user1.Username,
+		Limit:          100,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, users)
+	for _, user := range users {
+		require.NotEqual(t, user1.Username, user.Username)
+	}
+	require.Contains(t, users, user2)
+}
+
+func TestListUsersSearchLiteral(t *testing.T) {
+	prefix, suffix := utils.RandomOwnerName(), utils.RandomOwnerName()
+	var created []User
+	for _, username := range []string{prefix + "_" + suffix, prefix + "x" + suffix} {
+		hashedPassword, err := utils.RandomHashPassWord()
+		require.NoError(t, err)
+		user, err := testQueries.CreateUser(context.Background(), CreateUserParams{
+			Username:       username,
+			HashedPassword: hashedPassword,
+			FullName:       utils.RandomString(5),
+			Email:          utils.RandomEmail(),
+		})
+		require.NoError(t, err)
+		created = append(created, user)
+	}
+
+	// _ 只匹配下划线本身，不是任意一个字符
+	users, err := testQueries.ListUsers(context.Background(), ListUsersParams{
+		Search: sql.NullString{String: prefix + "_" + suffix, Valid: true},
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, created[0].Username, users[0].Username)
+
+	users, err = testQueries.ListUsers(context.Background(), ListUsersParams{
+		Search: sql.NullString{String: prefix + "%" + suffix, Valid: true},
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, users)
+}
+
+func TestDisableUser(t *testing.T) {
+	user, _ := createRandomUser(t)
+
+	disabledAt := time.Now()
+	disabled, err := testQueries.DisableUser(context.Background(), DisableUserParams{
+		Username:   user.Username,
+		DisabledAt: sql.NullTime{Time: disabledAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, disabled.DisabledAt.Valid)
+	require.WithinDuration(t, disabledAt, disabled.DisabledAt.Time, time.Second)
+
+	// 重复禁用不改变原来的禁用时间
+	again, err := testQueries.DisableUser(context.Background(), DisableUserParams{
+		Username:   user.Username,
+		DisabledAt: sql.NullTime{Time: disabledAt.Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, disabled.DisabledAt, again.DisabledAt)
+
+	enabled, err := testQueries.EnableUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, enabled.DisabledAt.Valid)
+}
+
+func TestDisableUserTx(t *testing.T) {
+	store := NewStore(testDB, testLedgerHashKey)
+	session := createRandomSession(t)
+
+	disabledAt := time.Now()
+	user, err := store.DisableUserTx(context.Background(), DisableUserTxParams{
+		DisableUserParams: DisableUserParams{
+			Username:   session.Username,
+			DisabledAt: sql.NullTime{Time: disabledAt, Valid: true},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, user.DisabledAt.Valid)
+
+	count, err := testQueries.CountActiveSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	got, err := testQueries.GetTokenNotBefore(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, disabledAt, got, time.Second)
+}
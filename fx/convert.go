@@ -0,0 +1,56 @@
+package fx
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+)
+
+var (
+	ErrInvalidRate   = errors.New("exchange rate must be a positive decimal number")
+	ErrAmountTooLow  = errors.New("amount is too small to convert at this rate")
+	ErrAmountTooHigh = errors.New("converted amount is too large")
+)
+
+// rateRegex matches the plain decimals that fit the numeric(24,12) rate
+// column; fractions and exponents are not rates
+var rateRegex = regexp.MustCompile(`^[0-9]{1,12}(\.[0-9]{1,12})?$`)
+
+// ParseRate parses a decimal exchange rate such as "1.0850", as stored in
+// the exchange_rates table
+func ParseRate(rate string) (*big.Rat, error) {
+	if !rateRegex.MatchString(rate) {
+		return nil, ErrInvalidRate
+	}
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return r, nil
+}
+
+// Convert converts amount, in minor units of a currency with fromUnits
+// decimals, into minor units of a currency with toUnits decimals. The rate is
+// quoted between major units, so 100 USD cents at 157 JPY per USD give 157
+// yen. The result is rounded down, so the bank never pays out more than the
+// rate allows.
+func Convert(amount int64, rate string, fromUnits, toUnits int32) (int64, error) {
+	r, err := ParseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), r)
+	product.Mul(product, new(big.Rat).SetFrac(pow10(toUnits), pow10(fromUnits)))
+	converted := new(big.Int).Quo(product.Num(), product.Denom())
+	if !converted.IsInt64() {
+		return 0, ErrAmountTooHigh
+	}
+	if converted.Sign() <= 0 {
+		return 0, ErrAmountTooLow
+	}
+	return converted.Int64(), nil
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,46 @@
+package fx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   int64
+		rate     string
+		from, to int32
+		expected int64
+		err      error
+	}{
+		{name: "Exact", amount: 100, rate: "1.5", expected: 150},
+		{name: "RoundsDown", amount: 100, rate: "0.919999999999", expected: 91},
+		{name: "LargeRate", amount: 7, rate: "157.25", expected: 1100},
+		{name: "TooLow", amount: 1, rate: "0.5", err: ErrAmountTooLow},
+		{name: "TooHigh", amount: math.MaxInt64, rate: "2", err: ErrAmountTooHigh},
+		{name: "ZeroRate", amount: 100, rate: "0", err: ErrInvalidRate},
+		{name: "NegativeRate", amount: 100, rate: "-1.2", err: ErrInvalidRate},
+		{name: "NotANumber", amount: 100, rate: "abc", err: ErrInvalidRate},
+		{name: "Fraction", amount: 100, rate: "1/3", err: ErrInvalidRate},
+		{name: "Exponent", amount: 100, rate: "1e2", err: ErrInvalidRate},
+		{name: "TooManyDecimals", amount: 100, rate: "0.1234567890123", err: ErrInvalidRate},
+		{name: "ToZeroDecimals", amount: 100, rate: "157.25", from: 2, to: 0, expected: 157},
+		{name: "FromZeroDecimals", amount: 1000, rate: "0.006359", from: 0, to: 2, expected: 635},
+		{name: "ToZeroDecimalsTooLow", amount: 1, rate: "0.5", from: 2, to: 0, err: ErrAmountTooLow},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := Convert(tc.amount, tc.rate, tc.from, tc.to)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package fx
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+// maxResponseSize bounds how much of a rate source is read
+const maxResponseSize = 1 << 20
+
+// maxClockSkew is how far in the future the time of a rate may be
+const maxClockSkew = time.Minute
+
+// Rate is the price of one unit of Base in Quote, as a decimal string, as of
+// the time the source published it
+type Rate struct {
+	Base  string    `json:"base"`
+	Quote string    `json:"quote"`
+	Rate  string    `json:"rate"`
+	AsOf  time.Time `json:"as_of"`
+}
+
+// RateProvider fetches the current exchange rates from a source
+type RateProvider interface {
+	Rates(ctx context.Context) ([]Rate, error)
+}
+
+// NewProvider returns an HTTP provider for http and https URLs and a file
+// provider for anything else
+func NewProvider(source string) RateProvider {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return NewHTTPProvider(source)
+	}
+	return &FileProvider{Path: source}
+}
+
+// FileProvider reads rates from a CSV file with base,quote,rate rows, or from
+// a JSON file when the name ends in .json. Rates without an as_of are as of
+// the modification time of the file, so a file nobody updates goes stale.
+type FileProvider struct {
+	Path string
+}
+
+func (provider *FileProvider) Rates(ctx context.Context) ([]Rate, error) {
+	file, err := os.Open(provider.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(provider.Path), ".json") {
+		return decodeJSON(file, info.ModTime())
+	}
+	return decodeCSV(file, info.ModTime())
+}
+
+// HTTPProvider fetches rates as JSON from a URL. Rates without an as_of are
+// as of the Last-Modified header; without either the document is rejected.
+type HTTPProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewHTTPProvider(url string) *HTTPProvider {
+	return &HTTPProvider{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (provider *HTTPProvider) Rates(ctx context.Context) ([]Rate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	rsp, err := provider.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rate source returned status %d", rsp.StatusCode)
+	}
+	// 没有Last-Modified时为零值，文档里必须带as_of
+	lastModified, _ := http.ParseTime(rsp.Header.Get("Last-Modified"))
+	return decodeJSON(io.LimitReader(rsp.Body, maxResponseSize), lastModified)
+}
+
+// decodeJSON reads a document of the form
+// {"as_of": "2024-01-02T15:04:05Z", "rates": [{"base": "USD", "quote": "EUR", "rate": "0.92"}]}.
+// A rate may carry its own as_of; otherwise the one of the document applies,
+// and then asOf.
+func decodeJSON(r io.Reader, asOf time.Time) ([]Rate, error) {
+	var doc struct {
+		AsOf  time.Time `json:"as_of"`
+		Rates []Rate    `json:"rates"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid rate document: %w", err)
+	}
+	if !doc.AsOf.IsZero() {
+		asOf = doc.AsOf
+	}
+	for i := range doc.Rates {
+		if doc.Rates[i].AsOf.IsZero() {
+			doc.Rates[i].AsOf = asOf
+		}
+	}
+	return validRates(doc.Rates)
+}
+
+// decodeCSV reads base,quote,rate rows, all as of asOf; a first row starting
+// with "base" is taken as a header
+func decodeCSV(r io.Reader, asOf time.Time) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate file: %w", err)
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "base") {
+		records = records[1:]
+	}
+
+	rates := make([]Rate, 0, len(records))
+	for _, record := range records {
+		rates = append(rates, Rate{Base: record[0], Quote: record[1], Rate: record[2], AsOf: asOf})
+	}
+	return validRates(rates)
+}
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validRates rejects the whole batch when a rate is malformed, but only skips
+// the pairs with a currency the bank does not know, since a feed usually
+// quotes many more currencies than the bank has
+func validRates(rates []Rate) ([]Rate, error) {
+	if len(rates) == 0 {
+		return nil, errors.New("rate source has no rates")
+	}
+	valid := make([]Rate, 0, len(rates))
+	for _, rate := range rates {
+		rate.Base = strings.ToUpper(strings.TrimSpace(rate.Base))
+		rate.Quote = strings.ToUpper(strings.TrimSpace(rate.Quote))
+		rate.Rate = strings.TrimSpace(rate.Rate)
+		if !currencyCodeRegex.MatchString(rate.Base) || !currencyCodeRegex.MatchString(rate.Quote) || rate.Base == rate.Quote {
+			return nil, fmt.Errorf("invalid currency pair %s/%s", rate.Base, rate.Quote)
+		}
+		if _, err := ParseRate(rate.Rate); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", rate.Base, rate.Quote, err)
+		}
+		if rate.AsOf.IsZero() {
+			return nil, fmt.Errorf("%s/%s: rate source does not say when the rate is from", rate.Base, rate.Quote)
+		}
+		rate.AsOf = rate.AsOf.UTC()
+		if rate.AsOf.After(time.Now().Add(maxClockSkew)) {
+			return nil, fmt.Errorf("%s/%s: rate is from the future: %s", rate.Base, rate.Quote, rate.AsOf)
+		}
+		_, baseOK := utils.LookupCurrency(rate.Base)
+		_, quoteOK := utils.LookupCurrency(rate.Quote)
+		if !baseOK || !quoteOK {
+			log.Warn().Str("base", rate.Base).Str("quote", rate.Quote).Msg("skip exchange rate of unsupported currency")
+			continue
+		}
+		valid = append(valid, rate)
+	}
+	if len(valid) == 0 {
+		return nil, errors.New("rate source has no rates of supported currencies")
+	}
+	return valid, nil
+}
@@ -0,0 +1,173 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fileModTime is the modification time of the rate files of the tests
+var fileModTime = time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+func writeRateFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(path, fileModTime, fileModTime))
+	return path
+}
+
+func TestFileProvider(t *testing.T) {
+	asOf := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		expected []Rate
+		wantErr  bool
+	}{
+		{
+			name:    "CSV",
+			file:    "rates.csv",
+			content: "base,quote,rate\nUSD,EUR,0.92\neur, usd, 1.087\n",
+			expected: []Rate{
+				{Base: "USD", Quote: "EUR", Rate: "0.92", AsOf: fileModTime},
+				{Base: "EUR", Quote: "USD", Rate: "1.087", AsOf: fileModTime},
+			},
+		},
+		{
+			name:     "CSVWithoutHeader",
+			file:     "rates.csv",
+			content:  "USD,JPY,157.25\n",
+			expected: []Rate{{Base: "USD", Quote: "JPY", Rate: "157.25", AsOf: fileModTime}},
+		},
+		{
+			name:     "JSON",
+			file:     "rates.json",
+			content:  `{"rates": [{"base": "GBP", "quote": "CAD", "rate": "1.74"}]}`,
+			expected: []Rate{{Base: "GBP", Quote: "CAD", Rate: "1.74", AsOf: fileModTime}},
+		},
+		{
+			name:    "JSONAsOf",
+			file:    "rates.json",
+			content: `{"as_of": "2024-05-01T12:00:00Z", "rates": [{"base": "GBP", "quote": "CAD", "rate": "1.74"}, {"base": "CAD", "quote": "GBP", "rate": "0.57", "as_of": "2024-05-01T11:00:00Z"}]}`,
+			expected: []Rate{
+				{Base: "GBP", Quote: "CAD", Rate: "1.74", AsOf: asOf},
+				{Base: "CAD", Quote: "GBP", Rate: "0.57", AsOf: asOf.Add(-time.Hour)},
+			},
+		},
+		{
+			name:    "FutureAsOf",
+			file:    "rates.json",
+			content: `{"as_of": "2999-01-01T00:00:00Z", "rates": [{"base": "GBP", "quote": "CAD", "rate": "1.74"}]}`,
+			wantErr: true,
+		},
+		{
+			name:     "SkipsUnsupportedCurrency",
+			file:     "rates.csv",
+			content:  "USD,XXX,1.5\nUSD,EUR,0.92\n",
+			expected: []Rate{{Base: "USD", Quote: "EUR", Rate: "0.92", AsOf: fileModTime}},
+		},
+		{
+			name:    "OnlyUnsupportedCurrencies",
+			file:    "rates.csv",
+			content: "USD,XXX,1.5\n",
+			wantErr: true,
+		},
+		{
+			name:    "MalformedCurrency",
+			file:    "rates.csv",
+			content: "USD,EURO,1.5\nUSD,EUR,0.92\n",
+			wantErr: true,
+		},
+		{
+			name:    "SameCurrency",
+			file:    "rates.csv",
+			content: "USD,USD,1\n",
+			wantErr: true,
+		},
+		{
+			name:    "InvalidRate",
+			file:    "rates.csv",
+			content: "USD,EUR,-0.92\n",
+			wantErr: true,
+		},
+		{
+			name:    "FractionRate",
+			file:    "rates.csv",
+			content: "USD,EUR,23/25\n",
+			wantErr: true,
+		},
+		{
+			name:    "Empty",
+			file:    "rates.json",
+			content: `{"rates": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "MalformedJSON",
+			file:    "rates.json",
+			content: `{"rates": [`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := NewProvider(writeRateFile(t, tc.file, tc.content))
+			rates, err := provider.Rates(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rates)
+		})
+	}
+}
+
+func TestFileProviderMissingFile(t *testing.T) {
+	provider := NewProvider(filepath.Join(t.TempDir(), "missing.csv"))
+	_, err := provider.Rates(context.Background())
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestHTTPProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rates":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Last-Modified", fileModTime.Format(http.TimeFormat))
+			w.Write([]byte(`{"rates": [{"base": "USD", "quote": "EUR", "rate": "0.92"}]}`))
+		case "/undated":
+			w.Write([]byte(`{"rates": [{"base": "USD", "quote": "EUR", "rate": "0.92"}]}`))
+		case "/broken":
+			w.Write([]byte(`not json`))
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL + "/rates")
+	require.IsType(t, &HTTPProvider{}, provider)
+	rates, err := provider.Rates(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Rate{{Base: "USD", Quote: "EUR", Rate: "0.92", AsOf: fileModTime}}, rates)
+
+	// 既没有as_of也没有Last-Modified，无法判断汇率是否过期
+	_, err = NewProvider(server.URL + "/undated").Rates(context.Background())
+	require.Error(t, err)
+
+	_, err = NewProvider(server.URL + "/broken").Rates(context.Background())
+	require.Error(t, err)
+
+	_, err = NewProvider(server.URL + "/down").Rates(context.Background())
+	require.ErrorContains(t, err, "503")
+}
@@ -0,0 +1,74 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+)
+
+var ErrStaleRate = errors.New("exchange rate is out of date")
+
+// RateStore is where refreshed rates are written. A batch is saved as a whole
+// or not at all.
+type RateStore interface {
+	CreateExchangeRatesTx(ctx context.Context, args []db.CreateExchangeRateParams) ([]db.ExchangeRate, error)
+}
+
+// Refresh fetches the rates of the provider and appends them to the store.
+// Earlier rates are kept as history; quotes use the latest rate of a pair.
+// When saving fails no rate of the fetch is kept, so the previous rates age
+// and are reported as stale instead of mixing with part of the new ones.
+func Refresh(ctx context.Context, provider RateProvider, store RateStore) ([]db.ExchangeRate, error) {
+	rates, err := provider.Rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]db.CreateExchangeRateParams, 0, len(rates))
+	for _, rate := range rates {
+		args = append(args, db.CreateExchangeRateParams{
+			BaseCurrency:  rate.Base,
+			QuoteCurrency: rate.Quote,
+			Rate:          rate.Rate,
+			AsOf:          rate.AsOf,
+		})
+	}
+	return store.CreateExchangeRatesTx(ctx, args)
+}
+
+// RunRefresher refreshes the rates right away and then every interval until
+// ctx is done. Failures are logged and retried at the next tick.
+func RunRefresher(ctx context.Context, provider RateProvider, store RateStore, interval time.Duration) {
+	refresh := func() {
+		rates, err := Refresh(ctx, provider, store)
+		if err != nil {
+			log.Error().Err(err).Msg("can not refresh exchange rates")
+			return
+		}
+		log.Info().Int("rates", len(rates)).Msg("exchange rates refreshed")
+	}
+
+	refresh()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
+
+// CheckFresh returns ErrStaleRate when a rate the source published at asOf is
+// older than maxAge. A zero maxAge accepts rates of any age.
+func CheckFresh(asOf time.Time, maxAge time.Duration) error {
+	if maxAge > 0 && time.Since(asOf) > maxAge {
+		return ErrStaleRate
+	}
+	return nil
+}
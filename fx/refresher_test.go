@@ -0,0 +1,87 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+)
+
+type staticProvider struct {
+	rates []Rate
+	err   error
+}
+
+func (provider staticProvider) Rates(ctx context.Context) ([]Rate, error) {
+	return provider.rates, provider.err
+}
+
+type memoryRateStore struct {
+	rates []db.ExchangeRate
+	err   error
+}
+
+func (store *memoryRateStore) CreateExchangeRatesTx(ctx context.Context, args []db.CreateExchangeRateParams) ([]db.ExchangeRate, error) {
+	if store.err != nil {
+		return nil, store.err
+	}
+	createdAt := time.Now()
+	rates := make([]db.ExchangeRate, 0, len(args))
+	for _, arg := range args {
+		rates = append(rates, db.ExchangeRate{
+			ID:            int64(len(store.rates) + len(rates) + 1),
+			BaseCurrency:  arg.BaseCurrency,
+			QuoteCurrency: arg.QuoteCurrency,
+			Rate:          arg.Rate,
+			CreatedAt:     createdAt,
+			AsOf:          arg.AsOf,
+		})
+	}
+	store.rates = append(store.rates, rates...)
+	return rates, nil
+}
+
+func TestRefresh(t *testing.T) {
+	store := &memoryRateStore{}
+	asOf := time.Now().Add(-2 * time.Hour)
+	provider := staticProvider{rates: []Rate{
+		{Base: "USD", Quote: "EUR", Rate: "0.92", AsOf: asOf},
+		{Base: "EUR", Quote: "USD", Rate: "1.087", AsOf: asOf},
+	}}
+
+	saved, err := Refresh(context.Background(), provider, store)
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+
+	// 每次刷新都追加新的记录，旧的汇率留作历史
+	_, err = Refresh(context.Background(), provider, store)
+	require.NoError(t, err)
+	require.Len(t, store.rates, 4)
+	require.Equal(t, "USD", store.rates[2].BaseCurrency)
+	require.Equal(t, "EUR", store.rates[2].QuoteCurrency)
+	require.Equal(t, "0.92", store.rates[2].Rate)
+
+	// 来源一直没有更新时，重新写入也不会让汇率显得新鲜
+	require.Equal(t, asOf, store.rates[2].AsOf)
+	require.ErrorIs(t, CheckFresh(store.rates[2].AsOf, time.Hour), ErrStaleRate)
+
+	_, err = Refresh(context.Background(), staticProvider{err: errors.New("source is down")}, store)
+	require.Error(t, err)
+	require.Len(t, store.rates, 4)
+
+	// 保存失败时这一批一条都不留
+	store.err = errors.New("db is down")
+	saved, err = Refresh(context.Background(), provider, store)
+	require.Error(t, err)
+	require.Empty(t, saved)
+	require.Len(t, store.rates, 4)
+}
+
+func TestCheckFresh(t *testing.T) {
+	require.NoError(t, CheckFresh(time.Now().Add(-time.Minute), time.Hour))
+	require.ErrorIs(t, CheckFresh(time.Now().Add(-2*time.Hour), time.Hour), ErrStaleRate)
+	require.NoError(t, CheckFresh(time.Now().Add(-24*time.Hour), 0))
+}
@@ -0,0 +1,14 @@
+package gapi
+
+import "github.com/zjr71163356/simplebank/pb"
+
+// AdminServer serves AdminService. It shares the store, token maker and
+// interceptors of Server, and rpcPermissions limits it to admins.
+type AdminServer struct {
+	pb.UnimplementedAdminServiceServer
+	*Server
+}
+
+func NewAdminServer(server *Server) *AdminServer {
+	return &AdminServer{Server: server}
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/revocation"
 	"github.com/zjr71163356/simplebank/token"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -15,7 +18,104 @@ const (
 	authorizationBearer = "bearer"
 )
 
-func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+type payloadContextKey struct{}
+
+// errNoPayload is returned to handlers that run without the auth interceptors
+var errNoPayload = errors.New("no authenticated user in context")
+
+// publicMethods are the RPCs that can be called without an access token. Every
+// other method has to authenticate and pass its rule in rpcPermissions.
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName:       true,
+	pb.SimpleBank_LoginUser_FullMethodName:        true,
+	pb.SimpleBank_RenewAccessToken_FullMethodName: true,
+	pb.SimpleBank_Logout_FullMethodName:           true,
+}
+
+// publicServices are whole services that can be called without an access token
+var publicServices = []string{
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+func isPublicMethod(method string) bool {
+	if publicMethods[method] {
+		return true
+	}
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryAuthInterceptor authenticates every unary call that is not public and
+// checks it against its rule in rpcPermissions before the handler runs. The
+// payload is handed on to the handler through the context.
+func (server *Server) UnaryAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isPublicMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	payload, err := server.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	return handler(context.WithValue(ctx, payloadContextKey{}, payload), req)
+}
+
+// StreamAuthInterceptor does the same as UnaryAuthInterceptor for streams
+func (server *Server) StreamAuthInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isPublicMethod(info.FullMethod) {
+		return handler(srv, stream)
+	}
+	payload, err := server.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return unauthenticatedError(err)
+	}
+	return handler(srv, &authenticatedStream{
+		ServerStream: stream,
+		ctx:          context.WithValue(stream.Context(), payloadContextKey{}, payload),
+	})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authPayload returns the payload the auth interceptors verified for this call
+func authPayload(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(payloadContextKey{}).(*token.Payload)
+	if !ok {
+		return nil, errNoPayload
+	}
+	return payload, nil
+}
+
+func (server *Server) authorize(ctx context.Context, method string) (*token.Payload, error) {
+	payload, err := server.verifyAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := rpcPermissions.Authorize(method, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// canAccessOwner reports whether the caller may act on resources of owner
+// in the current RPC
+func (server *Server) canAccessOwner(ctx context.Context, payload *token.Payload, owner string) bool {
+	method, _ := grpc.Method(ctx)
+	return rpcPermissions.CanAccessOwner(method, payload, owner)
+}
+
+func (server *Server) verifyAccessToken(ctx context.Context) (*token.Payload, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, fmt.Errorf("missing metadata")
@@ -41,6 +141,18 @@ func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error)
 		return nil, fmt.Errorf("failed to verify token: %w", err)
 
 	}
+	// 刷新token只能用来续期，不能直接调用接口
+	if err := payload.VerifyAccess(); err != nil {
+		return nil, err
+	}
+
+	revoked, err := server.revocations.IsRevoked(ctx, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, revocation.ErrTokenRevoked
+	}
 
 	return payload, nil
 }
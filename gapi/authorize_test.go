@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/zjr71163356/simplebank/db/mock"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/rbac"
+	"github.com/zjr71163356/simplebank/token"
+	"github.com/zjr71163356/simplebank/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func callWithToken(server *Server, method string, accessToken string) (bool, error) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer "+accessToken))
+	called := false
+	_, err := server.UnaryAuthInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		called = true
+		return nil, nil
+	})
+	return called, err
+}
+
+func TestRefreshTokenAfterLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	revocations := revocationStub{}
+	server := newTestServer(t, store, revocations)
+
+	username := utils.RandomOwnerName()
+	claims := []token.PayloadOption{token.WithRoles(rbac.RoleDepositor)}
+	accessToken, _, err := server.tokenMaker.CreateToken(username, time.Minute, claims...)
+	require.NoError(t, err)
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(username, time.Hour, append(claims, token.WithTokenType(token.TokenTypeRefresh))...)
+	require.NoError(t, err)
+
+	called, err := callWithToken(server, pb.SimpleBank_GetAccount_FullMethodName, accessToken)
+	require.NoError(t, err)
+	require.True(t, called)
+
+	session := db.Session{ID: refreshPayload.Id, Username: username, RefreshToken: refreshToken}
+	store.EXPECT().GetSession(gomock.Any(), session.ID).Times(1).Return(session, nil)
+	store.EXPECT().RevokeSessionTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.RevokeSessionTxParams) (int64, error) {
+			revocations[arg.ID] = true
+			return 1, nil
+		})
+	_, err = server.Logout(context.Background(), &pb.LogoutRequest{RefreshToken: refreshToken})
+	require.NoError(t, err)
+
+	// 退出登录后，刷新token也不能当作访问token调用接口
+	called, err = callWithToken(server, pb.SimpleBank_GetAccount_FullMethodName, refreshToken)
+	require.False(t, called)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// 即使没有被撤销，刷新token也会因为类型被拒绝
+	delete(revocations, refreshPayload.Id)
+	called, err = callWithToken(server, pb.SimpleBank_GetAccount_FullMethodName, refreshToken)
+	require.False(t, called)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Contains(t, err.Error(), token.ErrNotAccessToken.Error())
+}
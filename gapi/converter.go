@@ -7,11 +7,102 @@ import (
 )
 
 func convertUser(user db.User) *pb.User {
-	return &pb.User{
+	pbUser := &pb.User{
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Role:              user.Role,
+	}
+	if user.DisabledAt.Valid {
+		pbUser.DisabledAt = timestamppb.New(user.DisabledAt.Time)
+	}
+	return pbUser
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:             account.ID,
+		Owner:          account.Owner,
+		Balance:        account.Balance,
+		Currency:       account.Currency,
+		CreatedAt:      timestamppb.New(account.CreatedAt),
+		OverdraftLimit: account.OverdraftLimit,
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	pbTransfer := &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+	if transfer.QuoteID.Valid {
+		pbTransfer.ConvertedAmount = transfer.ConvertedAmount.Int64
+		pbTransfer.QuoteId = transfer.QuoteID.UUID.String()
+	}
+	return pbTransfer
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:                  entry.ID,
+		AccountId:           entry.AccountID,
+		Amount:              entry.Amount,
+		CreatedAt:           timestamppb.New(entry.CreatedAt),
+		TransferId:          entry.TransferID.Int64,
+		LedgerTransactionId: entry.LedgerTransactionID.Int64,
+	}
+}
+
+func convertLedgerTransaction(transaction db.LedgerTransaction) *pb.LedgerTransaction {
+	return &pb.LedgerTransaction{
+		Id:        transaction.ID,
+		Type:      transaction.Type,
+		AccountId: transaction.AccountID.Int64,
+		Amount:    transaction.Amount.Int64,
+		CreatedAt: timestamppb.New(transaction.CreatedAt),
+	}
+}
+
+func convertStatementLine(line db.StatementLine) *pb.StatementLine {
+	return &pb.StatementLine{
+		Entry: &pb.Entry{
+			Id:         line.ID,
+			AccountId:  line.AccountID,
+			Amount:     line.Amount,
+			CreatedAt:  timestamppb.New(line.CreatedAt),
+			TransferId: line.TransferID.Int64,
+		},
+		RunningBalance:        line.RunningBalance,
+		CounterpartyAccountId: line.CounterpartyAccountID,
+	}
+}
+
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		SessionId: session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		CreatedAt: timestamppb.New(session.CreatedAt),
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+	}
+}
+
+func convertAuditEvent(event db.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:         event.ID,
+		Actor:      event.Actor,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetId:   event.TargetID,
+		Before:     string(event.Before),
+		After:      string(event.After),
+		ClientIp:   event.ClientIp,
+		UserAgent:  event.UserAgent,
+		CreatedAt:  timestamppb.New(event.CreatedAt),
 	}
 }
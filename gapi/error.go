@@ -1,6 +1,9 @@
 package gapi
 
 import (
+	"errors"
+
+	"github.com/zjr71163356/simplebank/rbac"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -23,3 +26,10 @@ func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) er
 	return statusDetails.Err()
 
 }
+
+func unauthenticatedError(err error) error {
+	if errors.Is(err, rbac.ErrPermissionDenied) {
+		return status.Errorf(codes.PermissionDenied, "failed to authorize user: %v", err)
+	}
+	return status.Errorf(codes.Unauthenticated, "failed to authorize user: %v", err)
+}
@@ -0,0 +1,37 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const gatewayBufferSize = 1 << 20
+
+// gatewayNetwork is the network of the in-memory connection between the
+// gateway and the gRPC server
+const gatewayNetwork = "bufconn"
+
+// DialGateway serves grpcServer on an in-memory listener and returns a client
+// connection to it. The gateway talks to the server through this connection
+// instead of calling the handlers directly, so HTTP requests go through the
+// same interceptors as gRPC ones.
+func DialGateway(grpcServer *grpc.Server) (*grpc.ClientConn, error) {
+	listener := bufconn.Listen(gatewayBufferSize)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fail to dial gateway connection:%w", err)
+	}
+	return conn, nil
+}
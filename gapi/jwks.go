@@ -0,0 +1,30 @@
+package gapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/zjr71163356/simplebank/token"
+)
+
+// JWKSHandler publishes the public keys that verify our tokens. With a
+// symmetric token algorithm there is nothing to publish and it responds 404.
+func (server *Server) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		maker, ok := server.tokenMaker.(token.PublicKeyMaker)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(maker.JWKS())
+	})
+}
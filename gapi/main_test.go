@@ -0,0 +1,34 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/token"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func newTestServer(t *testing.T, store db.Store, revocations revocationStub) *Server {
+	config := utils.Config{
+		TokenSymmetricKey:    utils.RandomString(32),
+		PageTokenKey:         utils.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+	server, err := NewServer(config, store, revocations)
+	require.NoError(t, err)
+	return server
+}
+
+// revocationStub treats the tokens with the listed ids as revoked
+type revocationStub map[uuid.UUID]bool
+
+func (stub revocationStub) IsRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	return stub[payload.Id], nil
+}
+
+func (stub revocationStub) Purge() {}
@@ -2,11 +2,17 @@ package gapi
 
 import (
 	"context"
+	"strings"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/val"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
 
+const idempotencyKeyHeader = "idempotency-key"
+
 type MetaData struct {
 	UserAgent string
 	ClientIP  string
@@ -25,14 +31,59 @@ func (server *Server) extractMetadata(ctx context.Context) *MetaData {
 		}
 
 		if ClientIP := md.Get("x-forwarded-for"); len(ClientIP) > 0 {
-			mtdt.ClientIP = ClientIP[0]
+			mtdt.ClientIP = lastForwardedHop(ClientIP)
 		}
 	}
 
-	if p, ok := peer.FromContext(ctx); ok {
+	// 经过gateway的请求，peer是内存连接，客户端地址只能取x-forwarded-for
+	if p, ok := peer.FromContext(ctx); ok && p.Addr.Network() != gatewayNetwork {
 		mtdt.ClientIP = p.Addr.String()
 	}
 
 	return mtdt
 
 }
+
+// lastForwardedHop returns the address the gateway appended to
+// x-forwarded-for, which is the remote address of the HTTP request. The hops
+// before it, and any x-forwarded-for metadata ahead of the one the gateway
+// adds last, come from the client and can be forged.
+func lastForwardedHop(values []string) string {
+	hops := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(hops[len(hops)-1])
+}
+
+// auditParams describes the caller of the current RPC for the audit log
+func (server *Server) auditParams(ctx context.Context, actor string) *db.AuditParams {
+	mtdt := server.extractMetadata(ctx)
+	return &db.AuditParams{
+		Actor:     actor,
+		ClientIP:  mtdt.ClientIP,
+		UserAgent: mtdt.UserAgent,
+	}
+}
+
+// IncomingHeaderMatcher lets the gateway forward the Idempotency-Key header
+// as gRPC metadata on top of the default headers
+func IncomingHeaderMatcher(key string) (string, bool) {
+	if strings.ToLower(key) == idempotencyKeyHeader {
+		return idempotencyKeyHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// idempotencyParams returns nil when the client did not send an idempotency key
+func (server *Server) idempotencyParams(ctx context.Context, username string) (*db.IdempotencyParams, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	keys := md.Get(idempotencyKeyHeader)
+	if len(keys) == 0 || keys[0] == "" {
+		return nil, nil
+	}
+	if err := val.ValidateIdempotencyKey(keys[0]); err != nil {
+		return nil, err
+	}
+	return &db.IdempotencyParams{Username: username, Key: keys[0]}, nil
+}
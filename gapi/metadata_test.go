@@ -0,0 +1,75 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/zjr71163356/simplebank/db/mock"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/utils"
+	"google.golang.org/grpc"
+)
+
+// newTestGateway serves server over the gateway the way main does
+func newTestGateway(t *testing.T, server *Server) *httptest.Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(server.UnaryAuthInterceptor))
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	conn, err := DialGateway(grpcServer)
+	require.NoError(t, err)
+
+	grpcMux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(IncomingHeaderMatcher))
+	err = pb.RegisterSimpleBankHandlerClient(context.Background(), grpcMux, pb.NewSimpleBankClient(conn))
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(grpcMux)
+	t.Cleanup(func() {
+		httpServer.Close()
+		conn.Close()
+		grpcServer.Stop()
+	})
+	return httpServer
+}
+
+func TestGatewayForgedForwardedFor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store, revocationStub{})
+	gateway := newTestGateway(t, server)
+
+	username := utils.RandomOwnerName()
+	var audit *db.AuditParams
+	store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateUserTxParams) (db.User, error) {
+			audit = arg.Audit
+			return db.User{Username: arg.Username, FullName: arg.FullName, Email: arg.Email}, nil
+		})
+
+	body := `{"username": "` + username + `", "password": "secret", "full_name": "Test", "email": "` + username + `@example.com"}`
+	request, err := http.NewRequest(http.MethodPost, gateway.URL+"/v1/create_user", strings.NewReader(body))
+	require.NoError(t, err)
+	// 客户端伪造的地址排在gateway追加的真实地址之前
+	request.Header.Set("X-Forwarded-For", "203.0.113.7")
+	request.Header.Set("Grpc-Metadata-X-Forwarded-For", "198.51.100.9")
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	require.NotNil(t, audit)
+	require.Equal(t, "127.0.0.1", audit.ClientIP)
+}
+
+func TestLastForwardedHop(t *testing.T) {
+	require.Equal(t, "10.0.0.1", lastForwardedHop([]string{"10.0.0.1"}))
+	require.Equal(t, "10.0.0.1", lastForwardedHop([]string{"203.0.113.7, 10.0.0.1"}))
+	require.Equal(t, "10.0.0.1", lastForwardedHop([]string{"198.51.100.9", "203.0.113.7,10.0.0.1"}))
+}
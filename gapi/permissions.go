@@ -0,0 +1,95 @@
+package gapi
+
+import (
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/rbac"
+)
+
+// rpcPermissions holds the rule of every RPC that is not in publicMethods.
+// An RPC missing from both is denied to every token.
+var rpcPermissions = rbac.Table{
+	pb.SimpleBank_UpdateUser_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeUserWrite,
+	},
+	pb.SimpleBank_ListSessions_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeSessions,
+	},
+	pb.SimpleBank_RevokeSession_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeSessions,
+	},
+	pb.SimpleBank_RevokeAllSessions_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeSessions,
+	},
+	pb.SimpleBank_CreateAccount_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeAccountsWrite,
+	},
+	pb.SimpleBank_GetAccount_FullMethodName: {
+		Roles:         rbac.AllRoles,
+		Scope:         rbac.ScopeAccountsRead,
+		AnyOwnerRoles: rbac.StaffRoles,
+	},
+	pb.SimpleBank_ListAccounts_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeAccountsRead,
+	},
+	pb.SimpleBank_CreateTransfer_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeTransfersWrite,
+	},
+	pb.SimpleBank_GetTransferQuote_FullMethodName: {
+		Roles: rbac.AllRoles,
+		Scope: rbac.ScopeTransfersWrite,
+	},
+	pb.SimpleBank_GetStatement_FullMethodName: {
+		Roles:         rbac.AllRoles,
+		Scope:         rbac.ScopeAccountsRead,
+		AnyOwnerRoles: rbac.StaffRoles,
+	},
+	pb.SimpleBank_ListTransfers_FullMethodName: {
+		Roles:         rbac.AllRoles,
+		Scope:         rbac.ScopeAccountsRead,
+		AnyOwnerRoles: rbac.StaffRoles,
+	},
+	pb.SimpleBank_ListEntries_FullMethodName: {
+		Roles:         rbac.AllRoles,
+		Scope:         rbac.ScopeAccountsRead,
+		AnyOwnerRoles: rbac.StaffRoles,
+	},
+	pb.SimpleBank_Deposit_FullMethodName: {
+		Roles: rbac.StaffRoles,
+		Scope: rbac.ScopeLedgerWrite,
+	},
+	pb.SimpleBank_Withdraw_FullMethodName: {
+		Roles: rbac.StaffRoles,
+		Scope: rbac.ScopeLedgerWrite,
+	},
+	pb.AdminService_ListUsers_FullMethodName: {
+		Roles: []string{rbac.RoleAdmin},
+		Scope: rbac.ScopeUsersAdmin,
+	},
+	pb.AdminService_GetUser_FullMethodName: {
+		Roles: []string{rbac.RoleAdmin},
+		Scope: rbac.ScopeUsersAdmin,
+	},
+	pb.AdminService_DisableUser_FullMethodName: {
+		Roles: []string{rbac.RoleAdmin},
+		Scope: rbac.ScopeUsersAdmin,
+	},
+	pb.AdminService_EnableUser_FullMethodName: {
+		Roles: []string{rbac.RoleAdmin},
+		Scope: rbac.ScopeUsersAdmin,
+	},
+	pb.AdminService_ForceLogout_FullMethodName: {
+		Roles: []string{rbac.RoleAdmin},
+		Scope: rbac.ScopeUsersAdmin,
+	},
+	pb.AdminService_ListAuditEvents_FullMethodName: {
+		Roles: []string{rbac.RoleAdmin},
+		Scope: rbac.ScopeAuditRead,
+	},
+}
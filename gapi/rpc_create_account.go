@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateCreateAccountRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	idempotency, err := server.idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation(idempotencyKeyHeader, err)})
+	}
+
+	account, err := server.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    payload.Username,
+			Currency: req.GetCurrency(),
+			Balance:  0,
+		},
+		Idempotency: idempotency,
+		Audit:       server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+		}
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				return nil, status.Errorf(codes.AlreadyExists, "account already exists: %v", err)
+			case "foreign_key_violation":
+				return nil, status.Errorf(codes.PermissionDenied, "account owner does not exist: %v", err)
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %v", err)
+	}
+
+	rsp := &pb.CreateAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
+
+func ValidateCreateAccountRequest(req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAccountCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return violations
+}
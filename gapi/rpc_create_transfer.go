@@ -0,0 +1,165 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/token"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateCreateTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if req.QuoteId != nil {
+		return server.createFXTransfer(ctx, payload, req)
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account %d does not belong to user %s", fromAccount.ID, payload.Username)
+	}
+
+	_, err = server.validAccount(ctx, req.GetToAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	idempotency, err := server.idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation(idempotencyKeyHeader, err)})
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Idempotency:   idempotency,
+		Audit:         server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds):
+			return nil, status.Errorf(codes.FailedPrecondition, "account %d has insufficient funds", req.GetFromAccountId())
+		case errors.Is(err, db.ErrSystemAccount):
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		case errors.Is(err, db.ErrIdempotencyKeyConflict):
+			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer money: %v", err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+	return rsp, nil
+}
+
+// createFXTransfer transfers money to an account in another currency at the
+// rate locked by the quote
+func (server *Server) createFXTransfer(ctx context.Context, payload *token.Payload, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account %d does not belong to user %s", fromAccount.ID, payload.Username)
+	}
+
+	idempotency, err := server.idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation(idempotencyKeyHeader, err)})
+	}
+
+	result, err := server.store.FXTransferTx(ctx, db.FXTransferTxParams{
+		QuoteID:       uuid.MustParse(req.GetQuoteId()),
+		Username:      payload.Username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Idempotency:   idempotency,
+		Audit:         server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds):
+			return nil, status.Errorf(codes.FailedPrecondition, "account %d has insufficient funds", req.GetFromAccountId())
+		case errors.Is(err, db.ErrQuoteNotFound):
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		case errors.Is(err, db.ErrQuoteMismatch), errors.Is(err, db.ErrQuoteExpired), errors.Is(err, db.ErrQuoteUsed), errors.Is(err, db.ErrSystemAccount):
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		case errors.Is(err, db.ErrIdempotencyKeyConflict):
+			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer money: %v", err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		Transaction: convertLedgerTransaction(result.Transaction),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+	return rsp, nil
+}
+
+// validAccount checks that the account exists and holds the given currency
+func (server *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.getAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.FailedPrecondition, "account %d has a different currency %s", accountID, account.Currency)
+	}
+	return account, nil
+}
+
+func ValidateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("cannot transfer money to the same account")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if req.QuoteId != nil {
+		if _, err := uuid.Parse(req.GetQuoteId()); err != nil {
+			violations = append(violations, fieldViolation("quote_id", err))
+		}
+	}
+	return violations
+}
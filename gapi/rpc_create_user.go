@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"errors"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
 	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/rbac"
+	"github.com/zjr71163356/simplebank/token"
 	"github.com/zjr71163356/simplebank/utils"
 	"github.com/zjr71163356/simplebank/val"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -27,11 +30,14 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
 	}
 
-	user, err := server.store.CreateUser(ctx, db.CreateUserParams{
-		Username:       req.Username,
-		HashedPassword: HashedPassword,
-		FullName:       req.FullName,
-		Email:          req.Email,
+	user, err := server.store.CreateUserTx(ctx, db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.Username,
+			HashedPassword: HashedPassword,
+			FullName:       req.FullName,
+			Email:          req.Email,
+		},
+		Audit: server.auditParams(ctx, req.Username),
 	})
 
 	if err != nil {
@@ -51,6 +57,7 @@ func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 			Email:             user.Email,
 			PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 			CreatedAt:         timestamppb.New(user.CreatedAt),
+			Role:              user.Role,
 		},
 	}
 
@@ -74,10 +81,20 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 	if err != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "incorrect password")
 	}
+	if user.DisabledAt.Valid {
+		return nil, status.Errorf(codes.PermissionDenied, "user is disabled")
+	}
+
+	scopes, err := rbac.GrantScopes(user.Role, req.GetScopes(), nil)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("scopes", err)})
+	}
 
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
 		server.config.AccessTokenDuration,
+		token.WithRoles(user.Role),
+		token.WithScopes(scopes...),
 	)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
@@ -86,19 +103,30 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
 		server.config.RefreshTokenDuration,
+		token.WithRoles(user.Role),
+		token.WithScopes(scopes...),
+		token.WithTokenType(token.TokenTypeRefresh),
 	)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %s", err)
 	}
 	metadata := server.extractMetadata(ctx)
-	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.Id,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    metadata.UserAgent,
-		ClientIp:     metadata.ClientIP,
-		IsBlocked:    false,
-		ExpiresAt:    refreshPayload.ExpiredAt,
+	session, err := server.store.CreateSessionTx(ctx, db.CreateSessionTxParams{
+		CreateSessionParams: db.CreateSessionParams{
+			ID:           refreshPayload.Id,
+			Username:     user.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    metadata.UserAgent,
+			ClientIp:     metadata.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    refreshPayload.ExpiredAt,
+			FamilyID:     refreshPayload.Id,
+			AccessTokenID: uuid.NullUUID{
+				UUID:  accessPayload.Id,
+				Valid: true,
+			},
+		},
+		Audit: server.auditParams(ctx, user.Username),
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create session: %s", err)
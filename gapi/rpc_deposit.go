@@ -0,0 +1,90 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/token"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateDepositRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := server.postLedgerTx(ctx, payload, req.GetAccountId(), req.GetAmount(), req.GetCurrency(), server.store.DepositTx)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &pb.DepositResponse{
+		Transaction: convertLedgerTransaction(result.Transaction),
+		Account:     convertAccount(result.Account),
+		Entry:       convertEntry(result.Entry),
+	}
+	return rsp, nil
+}
+
+// postLedgerTx checks the account and moves money between it and the cash
+// account of the bank with post, a deposit or a withdrawal
+func (server *Server) postLedgerTx(
+	ctx context.Context,
+	payload *token.Payload,
+	accountID int64,
+	amount int64,
+	currency string,
+	post func(context.Context, db.LedgerTxParams) (db.LedgerTxResult, error),
+) (db.LedgerTxResult, error) {
+	var result db.LedgerTxResult
+	if _, err := server.validAccount(ctx, accountID, currency); err != nil {
+		return result, err
+	}
+
+	idempotency, err := server.idempotencyParams(ctx, payload.Username)
+	if err != nil {
+		return result, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation(idempotencyKeyHeader, err)})
+	}
+
+	result, err = post(ctx, db.LedgerTxParams{
+		AccountID:   accountID,
+		Amount:      amount,
+		Idempotency: idempotency,
+		Audit:       server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds):
+			return result, status.Errorf(codes.FailedPrecondition, "account %d has insufficient funds", accountID)
+		case errors.Is(err, db.ErrSystemAccount):
+			return result, status.Errorf(codes.FailedPrecondition, "%v", err)
+		case errors.Is(err, db.ErrIdempotencyKeyConflict):
+			return result, status.Errorf(codes.AlreadyExists, "%v", err)
+		}
+		return result, status.Errorf(codes.Internal, "failed to post ledger transaction: %v", err)
+	}
+	return result, nil
+}
+
+func ValidateDepositRequest(req *pb.DepositRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return violations
+}
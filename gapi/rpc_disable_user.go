@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *AdminServer) DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.DisableUserResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateAdminUsername(req.GetUsername()); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// 防止管理员把自己锁在外面
+	if req.GetUsername() == payload.Username {
+		return nil, status.Errorf(codes.FailedPrecondition, "can't disable yourself")
+	}
+
+	user, err := server.store.DisableUserTx(ctx, db.DisableUserTxParams{
+		DisableUserParams: db.DisableUserParams{
+			Username: req.GetUsername(),
+			DisabledAt: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		},
+		Audit: server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to disable user: %v", err)
+	}
+	server.revocations.Purge()
+
+	rsp := &pb.DisableUserResponse{
+		User: convertUser(user),
+	}
+	return rsp, nil
+}
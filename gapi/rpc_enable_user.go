@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *AdminServer) EnableUser(ctx context.Context, req *pb.EnableUserRequest) (*pb.EnableUserResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateAdminUsername(req.GetUsername()); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.store.EnableUserTx(ctx, db.EnableUserTxParams{
+		Username: req.GetUsername(),
+		Audit:    server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to enable user: %v", err)
+	}
+
+	rsp := &pb.EnableUserResponse{
+		User: convertUser(user),
+	}
+	return rsp, nil
+}
@@ -0,0 +1,44 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *AdminServer) ForceLogout(ctx context.Context, req *pb.ForceLogoutRequest) (*pb.ForceLogoutResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateAdminUsername(req.GetUsername()); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	err = server.store.RevokeAllSessionsTx(ctx, db.RevokeAllSessionsTxParams{
+		Username:  req.GetUsername(),
+		NotBefore: time.Now(),
+		Audit:     server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+	server.revocations.Purge()
+
+	return &pb.ForceLogoutResponse{}, nil
+}
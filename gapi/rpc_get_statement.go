@@ -0,0 +1,81 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxStatementRange = 366 * 24 * time.Hour
+
+func (server *Server) GetStatement(ctx context.Context, req *pb.GetStatementRequest) (*pb.GetStatementResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateGetStatementRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// 先检查归属再加载流水，避免替他人账户执行昂贵的查询
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %v", err)
+	}
+	if !server.canAccessOwner(ctx, payload, account.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the user")
+	}
+
+	statement, err := server.store.GetStatement(ctx, db.GetStatementParams{
+		AccountID: account.ID,
+		StartTime: req.GetStartTime().AsTime(),
+		EndTime:   req.GetEndTime().AsTime(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get statement: %v", err)
+	}
+
+	rsp := &pb.GetStatementResponse{
+		Account:        convertAccount(statement.Account),
+		OpeningBalance: statement.OpeningBalance,
+		ClosingBalance: statement.ClosingBalance,
+		Lines:          make([]*pb.StatementLine, 0, len(statement.Lines)),
+	}
+	for _, line := range statement.Lines {
+		rsp.Lines = append(rsp.Lines, convertStatementLine(line))
+	}
+	return rsp, nil
+}
+
+func ValidateGetStatementRequest(req *pb.GetStatementRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if req.GetStartTime() == nil {
+		violations = append(violations, fieldViolation("start_time", errors.New("start time is required")))
+	}
+	if req.GetEndTime() == nil {
+		violations = append(violations, fieldViolation("end_time", errors.New("end time is required")))
+	}
+	if req.GetStartTime() != nil && req.GetEndTime() != nil {
+		if err := val.ValidateTimeRange(req.GetStartTime().AsTime(), req.GetEndTime().AsTime(), maxStatementRange); err != nil {
+			violations = append(violations, fieldViolation("end_time", err))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,142 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/fx"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/utils"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) GetTransferQuote(ctx context.Context, req *pb.GetTransferQuoteRequest) (*pb.GetTransferQuoteResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateGetTransferQuoteRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.getAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account %d does not belong to user %s", fromAccount.ID, payload.Username)
+	}
+
+	toAccount, err := server.getAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if db.IsBankAccount(toAccount.Owner) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", db.ErrSystemAccount)
+	}
+	if fromAccount.Currency == toAccount.Currency {
+		return nil, status.Errorf(codes.FailedPrecondition, "accounts have the same currency %s, transfers between them need no quote", fromAccount.Currency)
+	}
+
+	rate, err := server.store.GetLatestExchangeRate(ctx, db.GetLatestExchangeRateParams{
+		BaseCurrency:  fromAccount.Currency,
+		QuoteCurrency: toAccount.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "no exchange rate from %s to %s", fromAccount.Currency, toAccount.Currency)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %v", err)
+	}
+
+	if err := fx.CheckFresh(rate.AsOf, server.config.FXRateMaxAge); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "exchange rate from %s to %s is out of date", fromAccount.Currency, toAccount.Currency)
+	}
+
+	fromCurrency, ok := utils.LookupCurrency(fromAccount.Currency)
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "currency %s is not supported", fromAccount.Currency)
+	}
+	toCurrency, ok := utils.LookupCurrency(toAccount.Currency)
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "currency %s is not supported", toAccount.Currency)
+	}
+
+	convertedAmount, err := fx.Convert(req.GetAmount(), rate.Rate, fromCurrency.MinorUnits, toCurrency.MinorUnits)
+	if err != nil {
+		if errors.Is(err, fx.ErrAmountTooLow) || errors.Is(err, fx.ErrAmountTooHigh) {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("amount", err)})
+		}
+		return nil, status.Errorf(codes.Internal, "failed to convert amount: %v", err)
+	}
+
+	quote, err := server.store.CreateTransferQuoteTx(ctx, db.CreateTransferQuoteTxParams{
+		CreateTransferQuoteParams: db.CreateTransferQuoteParams{
+			ID:              uuid.New(),
+			Username:        payload.Username,
+			FromAccountID:   fromAccount.ID,
+			ToAccountID:     toAccount.ID,
+			Amount:          req.GetAmount(),
+			ConvertedAmount: convertedAmount,
+			ExchangeRateID:  rate.ID,
+			Rate:            rate.Rate,
+			ExpiresAt:       time.Now().Add(server.config.QuoteDuration),
+		},
+		Audit: server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create transfer quote: %v", err)
+	}
+
+	rsp := &pb.GetTransferQuoteResponse{
+		Quote: &pb.TransferQuote{
+			Id:              quote.ID.String(),
+			FromAccountId:   quote.FromAccountID,
+			ToAccountId:     quote.ToAccountID,
+			FromCurrency:    fromAccount.Currency,
+			ToCurrency:      toAccount.Currency,
+			Amount:          quote.Amount,
+			ConvertedAmount: quote.ConvertedAmount,
+			Rate:            quote.Rate,
+			ExpiresAt:       timestamppb.New(quote.ExpiresAt),
+		},
+	}
+	return rsp, nil
+}
+
+// getAccount returns the account, or a NotFound error if it doesn't exist
+func (server *Server) getAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, status.Errorf(codes.NotFound, "account %d does not exist", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account %d: %v", accountID, err)
+	}
+	return account, nil
+}
+
+func ValidateGetTransferQuoteRequest(req *pb.GetTransferQuoteRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("cannot transfer money to the same account")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	return violations
+}
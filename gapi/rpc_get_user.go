@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *AdminServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if violations := ValidateAdminUsername(req.GetUsername()); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	activeSessions, err := server.store.CountActiveSessions(ctx, user.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count sessions: %v", err)
+	}
+
+	rsp := &pb.GetUserResponse{
+		User:           convertUser(user),
+		ActiveSessions: activeSessions,
+	}
+	return rsp, nil
+}
+
+// ValidateAdminUsername checks the username of the user an admin RPC acts on
+func ValidateAdminUsername(username string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(username); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+	return violations
+}
@@ -0,0 +1,107 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateListAccountsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if req.GetPageId() != 0 {
+		return server.listAccountsByOffset(ctx, payload.Username, req)
+	}
+
+	scope := "list_accounts:" + payload.Username
+	afterCreatedAt, afterID, err := server.pageTokens.Keyset(req.GetPageToken(), scope)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+
+	pageSize := pagination.PageSize(req.GetPageSize())
+	accounts, err := server.store.ListAccountsAfter(ctx, db.ListAccountsAfterParams{
+		Owner:          payload.Username,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          pageSize + 1,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %v", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{}
+	if len(accounts) > int(pageSize) {
+		accounts = accounts[:pageSize]
+		last := accounts[len(accounts)-1]
+		rsp.NextPageToken, err = server.pageTokens.Encode(pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, scope)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
+	}
+	rsp.Accounts = make([]*pb.Account, 0, len(accounts))
+	for _, account := range accounts {
+		rsp.Accounts = append(rsp.Accounts, convertAccount(account))
+	}
+	return rsp, nil
+}
+
+// listAccountsByOffset serves the deprecated page_id pagination.
+func (server *Server) listAccountsByOffset(ctx context.Context, owner string, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  owner,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %v", err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		rsp.Accounts = append(rsp.Accounts, convertAccount(account))
+	}
+	return rsp, nil
+}
+
+func ValidateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	return validatePagination(req.GetPageId(), req.GetPageSize(), req.GetPageToken())
+}
+
+// validatePagination checks either the deprecated page_id/page_size pair
+// or the page_size/page_token pair of keyset pagination.
+func validatePagination(pageID int32, pageSize int32, pageToken string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if pageID != 0 {
+		if err := val.ValidatePageID(pageID); err != nil {
+			violations = append(violations, fieldViolation("page_id", err))
+		}
+		if err := val.ValidatePageSize(pageSize); err != nil {
+			violations = append(violations, fieldViolation("page_size", err))
+		}
+		if pageToken != "" {
+			violations = append(violations, fieldViolation("page_token", errors.New("page token can't be combined with page id")))
+		}
+		return violations
+	}
+
+	if err := val.ValidateKeysetPageSize(pageSize); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
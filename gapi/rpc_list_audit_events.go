@@ -0,0 +1,89 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *AdminServer) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	if violations := ValidateListAuditEventsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.ListAuditEventsParams{}
+	if req.Actor != nil {
+		arg.Actor = sql.NullString{String: req.GetActor(), Valid: true}
+	}
+	if req.Action != nil {
+		arg.Action = sql.NullString{String: req.GetAction(), Valid: true}
+	}
+	if req.TargetType != nil {
+		arg.TargetType = sql.NullString{String: req.GetTargetType(), Valid: true}
+	}
+	if req.TargetId != nil {
+		arg.TargetID = sql.NullString{String: req.GetTargetId(), Valid: true}
+	}
+	if req.StartTime != nil {
+		arg.StartTime = sql.NullTime{Time: req.GetStartTime().AsTime(), Valid: true}
+	}
+	if req.EndTime != nil {
+		arg.EndTime = sql.NullTime{Time: req.GetEndTime().AsTime(), Valid: true}
+	}
+
+	// 分页token与过滤条件绑定，换了过滤条件的请求不能沿用旧的token
+	scope := fmt.Sprintf("list_audit_events:%v:%v:%v:%v:%v:%v",
+		arg.Actor,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.StartTime,
+		arg.EndTime,
+	)
+	var err error
+	arg.BeforeCreatedAt, arg.BeforeID, err = server.pageTokens.Keyset(req.GetPageToken(), scope)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+	arg.Limit = pageSize + 1
+
+	events, err := server.store.ListAuditEvents(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list audit events: %v", err)
+	}
+
+	rsp := &pb.ListAuditEventsResponse{}
+	if len(events) > int(pageSize) {
+		events = events[:pageSize]
+		last := events[len(events)-1]
+		rsp.NextPageToken, err = server.pageTokens.Encode(pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, scope)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
+	}
+	rsp.Events = make([]*pb.AuditEvent, 0, len(events))
+	for _, event := range events {
+		rsp.Events = append(rsp.Events, convertAuditEvent(event))
+	}
+	return rsp, nil
+}
+
+func ValidateListAuditEventsRequest(req *pb.ListAuditEventsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.StartTime != nil && req.EndTime != nil && !req.GetStartTime().AsTime().Before(req.GetEndTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", errors.New("start time must be before end time")))
+	}
+	if err := val.ValidateKeysetPageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListEntries(ctx context.Context, req *pb.ListEntriesRequest) (*pb.ListEntriesResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateListEntriesRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %v", err)
+	}
+	if !server.canAccessOwner(ctx, payload, account.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the user")
+	}
+
+	scope := fmt.Sprintf("list_entries:%s:%d", payload.Username, account.ID)
+	afterCreatedAt, afterID, err := server.pageTokens.Keyset(req.GetPageToken(), scope)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+
+	pageSize := pagination.PageSize(req.GetPageSize())
+	entries, err := server.store.ListEntriesAfter(ctx, db.ListEntriesAfterParams{
+		AccountID:      account.ID,
+		AfterCreatedAt: afterCreatedAt,
+		AfterID:        afterID,
+		Limit:          pageSize + 1,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list entries: %v", err)
+	}
+
+	rsp := &pb.ListEntriesResponse{}
+	if len(entries) > int(pageSize) {
+		entries = entries[:pageSize]
+		last := entries[len(entries)-1]
+		rsp.NextPageToken, err = server.pageTokens.Encode(pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, scope)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
+	}
+	rsp.Entries = make([]*pb.Entry, 0, len(entries))
+	for _, entry := range entries {
+		rsp.Entries = append(rsp.Entries, convertEntry(entry))
+	}
+	return rsp, nil
+}
+
+func ValidateListEntriesRequest(req *pb.ListEntriesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateKeysetPageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
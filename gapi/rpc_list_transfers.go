@@ -0,0 +1,158 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateListTransfersRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// 指定账户时按账户的所有者查询，工作人员可以查看其他用户的账户
+	owner := payload.Username
+	if req.AccountId != nil {
+		account, err := server.store.GetAccount(ctx, req.GetAccountId())
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, status.Errorf(codes.NotFound, "account not found")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to get account: %v", err)
+		}
+		if !server.canAccessOwner(ctx, payload, account.Owner) {
+			return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the user")
+		}
+		owner = account.Owner
+	}
+
+	arg := db.FilterTransfersParams{
+		Owner:           owner,
+		IncludeOutgoing: req.GetDirection() != pb.TransferDirection_TRANSFER_DIRECTION_INCOMING,
+		IncludeIncoming: req.GetDirection() != pb.TransferDirection_TRANSFER_DIRECTION_OUTGOING,
+	}
+	if req.AccountId != nil {
+		arg.AccountID = sql.NullInt64{Int64: req.GetAccountId(), Valid: true}
+	}
+	if req.CounterpartyAccountId != nil {
+		arg.CounterpartyAccountID = sql.NullInt64{Int64: req.GetCounterpartyAccountId(), Valid: true}
+	}
+	if req.MinAmount != nil {
+		arg.MinAmount = sql.NullInt64{Int64: req.GetMinAmount(), Valid: true}
+	}
+	if req.MaxAmount != nil {
+		arg.MaxAmount = sql.NullInt64{Int64: req.GetMaxAmount(), Valid: true}
+	}
+	if req.StartTime != nil {
+		arg.StartTime = sql.NullTime{Time: req.GetStartTime().AsTime(), Valid: true}
+	}
+	if req.EndTime != nil {
+		arg.EndTime = sql.NullTime{Time: req.GetEndTime().AsTime(), Valid: true}
+	}
+
+	if req.GetPageId() != 0 {
+		arg.Limit = req.GetPageSize()
+		arg.Offset = (req.GetPageId() - 1) * req.GetPageSize()
+
+		transfers, err := server.store.FilterTransfers(ctx, arg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to list transfers: %v", err)
+		}
+
+		rsp := &pb.ListTransfersResponse{
+			Transfers: make([]*pb.Transfer, 0, len(transfers)),
+		}
+		for _, transfer := range transfers {
+			rsp.Transfers = append(rsp.Transfers, convertTransfer(transfer))
+		}
+		return rsp, nil
+	}
+
+	// 分页token与过滤条件绑定，换了过滤条件的请求不能沿用旧的token
+	scope := fmt.Sprintf("list_transfers:%s:%v:%v:%v:%v:%v:%v:%v:%v",
+		arg.Owner,
+		arg.AccountID,
+		arg.IncludeOutgoing,
+		arg.IncludeIncoming,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartTime,
+		arg.EndTime,
+	)
+	arg.AfterCreatedAt, arg.AfterID, err = server.pageTokens.Keyset(req.GetPageToken(), scope)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+	}
+	pageSize := pagination.PageSize(req.GetPageSize())
+	arg.Limit = pageSize + 1
+
+	transfers, err := server.store.FilterTransfers(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfers: %v", err)
+	}
+
+	rsp := &pb.ListTransfersResponse{}
+	if len(transfers) > int(pageSize) {
+		transfers = transfers[:pageSize]
+		last := transfers[len(transfers)-1]
+		rsp.NextPageToken, err = server.pageTokens.Encode(pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}, scope)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
+	}
+	rsp.Transfers = make([]*pb.Transfer, 0, len(transfers))
+	for _, transfer := range transfers {
+		rsp.Transfers = append(rsp.Transfers, convertTransfer(transfer))
+	}
+	return rsp, nil
+}
+
+func ValidateListTransfersRequest(req *pb.ListTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.AccountId != nil {
+		if err := val.ValidateID(req.GetAccountId()); err != nil {
+			violations = append(violations, fieldViolation("account_id", err))
+		}
+	}
+	if _, ok := pb.TransferDirection_name[int32(req.GetDirection())]; !ok {
+		violations = append(violations, fieldViolation("direction", errors.New("unknown transfer direction")))
+	}
+	if req.CounterpartyAccountId != nil {
+		if err := val.ValidateID(req.GetCounterpartyAccountId()); err != nil {
+			violations = append(violations, fieldViolation("counterparty_account_id", err))
+		}
+	}
+	if req.MinAmount != nil {
+		if err := val.ValidateAmount(req.GetMinAmount()); err != nil {
+			violations = append(violations, fieldViolation("min_amount", err))
+		}
+	}
+	if req.MaxAmount != nil {
+		if err := val.ValidateAmount(req.GetMaxAmount()); err != nil {
+			violations = append(violations, fieldViolation("max_amount", err))
+		}
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && req.GetMinAmount() > req.GetMaxAmount() {
+		violations = append(violations, fieldViolation("max_amount", errors.New("max amount must not be less than min amount")))
+	}
+	if req.StartTime != nil && req.EndTime != nil && !req.GetStartTime().AsTime().Before(req.GetEndTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", errors.New("start time must be before end time")))
+	}
+	violations = append(violations, validatePagination(req.GetPageId(), req.GetPageSize(), req.GetPageToken())...)
+	return violations
+}
@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxUserSearchLength = 100
+
+func (server *AdminServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	if violations := ValidateListUsersRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scope := "list_users:" + req.GetSearch()
+	var afterCreatedAt sql.NullTime
+	var afterUsername string
+	if req.GetPageToken() != "" {
+		cursor, err := server.pageTokens.Decode(req.GetPageToken(), scope)
+		if err != nil {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+		}
+		afterCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		afterUsername = cursor.Key
+	}
+
+	pageSize := pagination.PageSize(req.GetPageSize())
+	users, err := server.store.ListUsers(ctx, db.ListUsersParams{
+		Search: sql.NullString{
+			String: req.GetSearch(),
+			Valid:  req.GetSearch() != "",
+		},
+		AfterCreatedAt: afterCreatedAt,
+		AfterUsername:  afterUsername,
+		Limit:          pageSize + 1,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list users: %v", err)
+	}
+
+	rsp := &pb.ListUsersResponse{}
+	if len(users) > int(pageSize) {
+		users = users[:pageSize]
+		last := users[len(users)-1]
+		rsp.NextPageToken, err = server.pageTokens.Encode(pagination.Cursor{CreatedAt: last.CreatedAt, Key: last.Username}, scope)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
+	}
+	rsp.Users = make([]*pb.User, 0, len(users))
+	for _, user := range users {
+		rsp.Users = append(rsp.Users, convertUser(user))
+	}
+	return rsp, nil
+}
+
+func ValidateListUsersRequest(req *pb.ListUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.VaildateStringLen(req.GetSearch(), 0, maxUserSearchLength); err != nil {
+		violations = append(violations, fieldViolation("search", err))
+	}
+	if err := val.ValidateKeysetPageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	if violations := ValidateLogoutRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %v", err)
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %v", err)
+	}
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "session refresh token does not match request")
+	}
+
+	_, err = server.store.RevokeSessionTx(ctx, db.RevokeSessionTxParams{
+		RevokeSessionParams: db.RevokeSessionParams{
+			ID:       session.ID,
+			Username: session.Username,
+		},
+		Audit: server.auditParams(ctx, session.Username),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke session: %v", err)
+	}
+	server.revocations.Purge()
+
+	return &pb.LogoutResponse{}, nil
+}
+
+func ValidateLogoutRequest(req *pb.LogoutRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("refresh token is required")))
+	}
+	return violations
+}
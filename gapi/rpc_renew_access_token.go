@@ -0,0 +1,138 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/rbac"
+	"github.com/zjr71163356/simplebank/token"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	if violations := ValidateRenewAccessTokenRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %v", err)
+	}
+
+	revoked, err := server.revocations.IsRevoked(ctx, refreshPayload)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check token revocation: %v", err)
+	}
+	if revoked {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token has been revoked")
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %v", err)
+	}
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "session refresh token does not match request")
+	}
+	if session.Username != refreshPayload.Username {
+		return nil, status.Errorf(codes.Unauthenticated, "session user does not match token user")
+	}
+	if session.IsBlocked {
+		return nil, status.Errorf(codes.PermissionDenied, "session is blocked")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, status.Errorf(codes.PermissionDenied, "session is expired")
+	}
+
+	// 角色以数据库为准，降级或禁用的用户不能靠续期保留原有权限
+	user, err := server.store.GetUser(ctx, session.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user.DisabledAt.Valid {
+		return nil, status.Errorf(codes.PermissionDenied, "user is disabled")
+	}
+
+	// 续期不能扩大原token的权限，只能收窄scope
+	scopes, err := rbac.GrantScopes(user.Role, req.GetScopes(), refreshPayload.Scopes)
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("scopes", err)})
+	}
+	claims := []token.PayloadOption{
+		token.WithRoles(user.Role),
+		token.WithScopes(scopes...),
+	}
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		session.Username,
+		server.config.AccessTokenDuration,
+		claims...,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(
+		session.Username,
+		server.config.RefreshTokenDuration,
+		append(claims, token.WithTokenType(token.TokenTypeRefresh))...,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %s", err)
+	}
+
+	metadata := server.extractMetadata(ctx)
+	newSession, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newRefreshPayload.Id,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    metadata.UserAgent,
+			ClientIp:     metadata.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+			AccessTokenID: uuid.NullUUID{
+				UUID:  accessPayload.Id,
+				Valid: true,
+			},
+		},
+		Audit: server.auditParams(ctx, session.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenReused) {
+			server.revocations.Purge()
+			return nil, status.Errorf(codes.PermissionDenied, "refresh token has already been used, all sessions of this login are blocked")
+		}
+		if errors.Is(err, db.ErrSessionBlocked) {
+			return nil, status.Errorf(codes.PermissionDenied, "session is blocked")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to rotate session: %s", err)
+	}
+
+	rsp := &pb.RenewAccessTokenResponse{
+		SessionId:             newSession.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(newRefreshPayload.ExpiredAt),
+	}
+	return rsp, nil
+}
+
+func ValidateRenewAccessTokenRequest(req *pb.RenewAccessTokenRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("refresh token is required")))
+	}
+	return violations
+}
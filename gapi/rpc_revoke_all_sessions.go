@@ -0,0 +1,30 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	err = server.store.RevokeAllSessionsTx(ctx, db.RevokeAllSessionsTxParams{
+		Username:  payload.Username,
+		NotBefore: time.Now(),
+		Audit:     server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+	server.revocations.Purge()
+
+	return &pb.RevokeAllSessionsResponse{}, nil
+}
@@ -0,0 +1,48 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateRevokeSessionRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	sessionID, _ := uuid.Parse(req.GetSessionId())
+	revoked, err := server.store.RevokeSessionTx(ctx, db.RevokeSessionTxParams{
+		RevokeSessionParams: db.RevokeSessionParams{
+			ID:       sessionID,
+			Username: payload.Username,
+		},
+		Audit: server.auditParams(ctx, payload.Username),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke session: %v", err)
+	}
+	if revoked == 0 {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+	server.revocations.Purge()
+
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+func ValidateRevokeSessionRequest(req *pb.RevokeSessionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if _, err := uuid.Parse(req.GetSessionId()); err != nil {
+		violations = append(violations, fieldViolation("session_id", err))
+	}
+	return violations
+}
@@ -20,9 +20,9 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		return nil, invalidArgumentError(violations)
 	}
 
-	payload, err := server.authorizeUser(ctx)
+	payload, err := authPayload(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, "failed to authorize user: %v", err)
+		return nil, unauthenticatedError(err)
 	}
 
 	if payload.Username != req.GetUsername() {
@@ -56,13 +56,19 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		}
 	}
 
-	updatedUser, err := server.store.UpdateUser(ctx, arg)
+	updatedUser, err := server.store.UpdateUserTx(ctx, db.UpdateUserTxParams{
+		UpdateUserParams: arg,
+		Audit:            server.auditParams(ctx, payload.Username),
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
 	}
+	if arg.PasswordChangedAt.Valid {
+		server.revocations.Purge()
+	}
 
 	rsp := &pb.UpdateUserResponse{
 		User: &pb.User{
@@ -71,6 +77,7 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 			Email:             updatedUser.Email,
 			PasswordChangedAt: timestamppb.New(updatedUser.PasswordChangedAt),
 			CreatedAt:         timestamppb.New(updatedUser.CreatedAt),
+			Role:              updatedUser.Role,
 		},
 	}
 
@@ -0,0 +1,45 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	payload, err := authPayload(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if violations := ValidateWithdrawRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := server.postLedgerTx(ctx, payload, req.GetAccountId(), req.GetAmount(), req.GetCurrency(), server.store.WithdrawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &pb.WithdrawResponse{
+		Transaction: convertLedgerTransaction(result.Transaction),
+		Account:     convertAccount(result.Account),
+		Entry:       convertEntry(result.Entry),
+	}
+	return rsp, nil
+}
+
+func ValidateWithdrawRequest(req *pb.WithdrawRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return violations
+}
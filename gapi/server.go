@@ -4,24 +4,45 @@ import (
 	"fmt"
 
 	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
 	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/revocation"
 	"github.com/zjr71163356/simplebank/token"
 	"github.com/zjr71163356/simplebank/utils"
 )
 
 type Server struct {
 	pb.UnimplementedSimpleBankServer
-	config     utils.Config
-	store      db.Store
-	tokenMaker token.Maker
+	config      utils.Config
+	store       db.Store
+	tokenMaker  token.Maker
+	pageTokens  *pagination.PageTokenCodec
+	revocations revocation.Checker
 }
 
-func NewServer(config utils.Config, store db.Store) (*Server, error) {
-	maker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+// NewServer creates a server checking tokens against revocations, which is
+// shared with the other servers of the process so a revocation is seen by all
+func NewServer(config utils.Config, store db.Store, revocations revocation.Checker) (*Server, error) {
+	maker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("fail to create token:%w", err)
 	}
-	server := &Server{config: config, store: store, tokenMaker: maker}
+	pageTokens, err := pagination.NewPageTokenCodec(config.PageTokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create page token codec, check PAGE_TOKEN_KEY:%w", err)
+	}
+	server := &Server{
+		config:      config,
+		store:       store,
+		tokenMaker:  maker,
+		pageTokens:  pageTokens,
+		revocations: revocations,
+	}
 	
 	return server, nil
 }
+
+// ReloadTokenKeys swaps in the token keys of the reloaded config
+func (server *Server) ReloadTokenKeys(config utils.Config) error {
+	return token.ReloadKeys(server.tokenMaker, config)
+}
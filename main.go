@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -19,8 +21,10 @@ import (
 	"github.com/zjr71163356/simplebank/api"
 	db "github.com/zjr71163356/simplebank/db/sqlc"
 	_ "github.com/zjr71163356/simplebank/doc/statik"
+	"github.com/zjr71163356/simplebank/fx"
 	"github.com/zjr71163356/simplebank/gapi"
 	"github.com/zjr71163356/simplebank/pb"
+	"github.com/zjr71163356/simplebank/revocation"
 	"github.com/zjr71163356/simplebank/utils"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -46,10 +50,179 @@ func main() {
 
 	runDBMigration(config.MigrateURL, config.DBSource)
 
-	store := db.NewStore(conn)
-	go runGRPCServer(config, store)
-	runGRPCGatewayServer(config, store)
+	if len(config.LedgerHashKey) < db.MinLedgerHashKeySize {
+		log.Fatal().Msgf("LEDGER_HASH_KEY must be at least %d characters", db.MinLedgerHashKeySize)
+	}
+	store := db.NewStore(conn, config.LedgerHashKey)
+	if len(os.Args) > 1 && os.Args[1] == "verify-ledger" {
+		runVerifyLedger(store, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "anchor-ledger" {
+		runAnchorLedger(store)
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		if !reconcileLedger(context.Background(), store) {
+			os.Exit(1)
+		}
+		return
+	}
+	loadCurrencies(store)
+	go runReconciliationJob(config, store)
+	go runFXRefresher(config, store)
+	// 所有服务共用一个撤销检查器，一处撤销后各处的缓存同时失效
+	revocations := revocation.NewCachedChecker(store, config.RevocationCacheSize, config.RevocationCacheTTL)
+	go runGRPCServer(config, store, revocations)
+	runGRPCGatewayServer(config, store, revocations)
+}
+
+// runVerifyLedger checks the hash chain of the entries of one account, or of
+// every account when no id is given, and exits with status 1 if one is broken
+func runVerifyLedger(store db.Store, args []string) {
+	ctx := context.Background()
+	var breaks []db.ChainBreak
+	if len(args) > 0 {
+		accountID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid account id")
+		}
+		chainBreak, err := store.VerifyEntryChain(ctx, accountID)
+		if err != nil {
+			log.Fatal().Err(err).Msg("can not verify ledger")
+		}
+		if chainBreak != nil {
+			breaks = append(breaks, *chainBreak)
+		}
+	} else {
+		var err error
+		breaks, err = store.VerifyLedger(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("can not verify ledger")
+		}
+	}
+
+	for _, chainBreak := range breaks {
+		log.Error().
+			Int64("account_id", chainBreak.AccountID).
+			Int64("entry_id", chainBreak.EntryID).
+			Str("reason", chainBreak.Reason).
+			Msg("ledger hash chain is broken")
+	}
+	if len(breaks) > 0 {
+		os.Exit(1)
+	}
+	log.Info().Msg("ledger hash chain verified")
+}
+
+// runAnchorLedger anchors the head of every chain written before heads
+// existed, so that verify-ledger also detects entries removed from its end
+func runAnchorLedger(store db.Store) {
+	breaks, err := store.AnchorLedger(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("can not anchor ledger")
+	}
+	for _, chainBreak := range breaks {
+		log.Error().
+			Int64("account_id", chainBreak.AccountID).
+			Int64("entry_id", chainBreak.EntryID).
+			Str("reason", chainBreak.Reason).
+			Msg("ledger hash chain is broken")
+	}
+	if len(breaks) > 0 {
+		os.Exit(1)
+	}
+	log.Info().Msg("ledger hash chain anchored")
+}
+
+// loadCurrencies replaces the built-in currencies of utils with the
+// currencies table, so that every validator accepts the enabled ones
+func loadCurrencies(store db.Store) {
+	rows, err := store.ListCurrencies(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("can not load currencies")
+	}
+	currencies := make([]utils.Currency, 0, len(rows))
+	for _, row := range rows {
+		currencies = append(currencies, utils.Currency{
+			Code:       row.Code,
+			MinorUnits: row.MinorUnits,
+			Enabled:    row.Enabled,
+		})
+	}
+	utils.SetCurrencies(currencies)
+	log.Info().Strs("currencies", utils.SupportedCurrencies()).Msg("loaded currencies")
+}
+
+// runReconciliationJob reconciles the ledger every ReconciliationInterval.
+// A zero interval disables the job.
+func runReconciliationJob(config utils.Config, store db.Store) {
+	if config.ReconciliationInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(config.ReconciliationInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		reconcileLedger(context.Background(), store)
+	}
+}
+
+// runFXRefresher keeps the exchange rates up to date from FXRateSource, a
+// file or an http(s) URL. An empty source or a zero interval disables it.
+func runFXRefresher(config utils.Config, store db.Store) {
+	if config.FXRateSource == "" || config.FXRefreshInterval <= 0 {
+		return
+	}
+	fx.RunRefresher(context.Background(), fx.NewProvider(config.FXRateSource), store, config.FXRefreshInterval)
+}
+
+// reconcileLedger logs every discrepancy between balances, entries, transfers
+// and ledger transactions, and reports whether the ledger is consistent
+func reconcileLedger(ctx context.Context, store db.Store) bool {
+	report, err := store.Reconcile(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("can not reconcile ledger")
+		return false
+	}
+
+	for _, mismatch := range report.BalanceMismatches {
+		log.Error().
+			Int64("account_id", mismatch.AccountID).
+			Int64("balance", mismatch.Balance).
+			Int64("entries_total", mismatch.EntriesTotal).
+			Msg("account balance does not match its entries")
+	}
+	for _, transfer := range report.UnmatchedTransfers {
+		log.Error().
+			Int64("transfer_id", transfer.TransferID).
+			Int64("from_account_id", transfer.FromAccountID).
+			Int64("to_account_id", transfer.ToAccountID).
+			Int64("amount", transfer.Amount).
+			Int64("entry_count", transfer.EntryCount).
+			Msg("transfer does not have two matching entries")
+	}
+	for _, transaction := range report.UnbalancedLedgerTransactions {
+		log.Error().
+			Int64("ledger_transaction_id", transaction.LedgerTransactionID).
+			Str("type", transaction.Type).
+			Int64("account_id", transaction.AccountID.Int64).
+			Int64("amount", transaction.Amount.Int64).
+			Int64("entry_count", transaction.EntryCount).
+			Int64("entries_total", transaction.EntriesTotal).
+			Msg("ledger transaction is not balanced by its entries")
+	}
+	if !report.Clean() {
+		log.Error().
+			Int("balance_mismatches", len(report.BalanceMismatches)).
+			Int("unmatched_transfers", len(report.UnmatchedTransfers)).
+			Int("unbalanced_ledger_transactions", len(report.UnbalancedLedgerTransactions)).
+			Msg("ledger reconciliation found discrepancies")
+		return false
+	}
+	log.Info().Msg("ledger reconciled")
+	return true
 }
+
 func runDBMigration(migrateURL string, dbSource string) {
 	migration, err := migrate.New(migrateURL, dbSource)
 
@@ -64,24 +237,45 @@ func runDBMigration(migrateURL string, dbSource string) {
 	log.Info().Msg("db migration completed")
 }
 
-func runGinServer(config utils.Config, store db.Store) {
-	server, err := api.NewServer(config, store)
+func runGinServer(config utils.Config, store db.Store, revocations revocation.Checker) {
+	server, err := api.NewServer(config, store, revocations)
 	if err != nil {
 		log.Fatal().Err(err).Msg("can not create server")
 	}
+	reloadTokenKeysOnChange(server.ReloadTokenKeys)
 	server.Start(config.HTTPServerAddress)
 }
 
-func runGRPCServer(config utils.Config, store db.Store) {
-	server, err := gapi.NewServer(config, store)
+// reloadTokenKeysOnChange rotates the token keys whenever app.env changes,
+// without logging out users whose tokens were signed with a previous key
+func reloadTokenKeysOnChange(reload func(utils.Config) error) {
+	utils.WatchConfig(func(config utils.Config, err error) {
+		if err == nil {
+			err = reload(config)
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("can not reload token keys")
+			return
+		}
+		log.Info().Msg("token keys reloaded")
+	})
+}
+
+func runGRPCServer(config utils.Config, store db.Store, revocations revocation.Checker) {
+	server, err := gapi.NewServer(config, store, revocations)
 
 	if err != nil {
 		log.Fatal().Err(err).Msg("can not create server")
 	}
 
-	interceptor := grpc.UnaryInterceptor(gapi.GrpcLogger)
-	grpcServer := grpc.NewServer(interceptor)
+	reloadTokenKeysOnChange(server.ReloadTokenKeys)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(gapi.GrpcLogger, server.UnaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(server.StreamAuthInterceptor),
+	)
 	pb.RegisterSimpleBankServer(grpcServer, server)
+	pb.RegisterAdminServiceServer(grpcServer, gapi.NewAdminServer(server))
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", config.GRPCServerAddress)
@@ -96,12 +290,14 @@ func runGRPCServer(config utils.Config, store db.Store) {
 	}
 
 }
-func runGRPCGatewayServer(config utils.Config, store db.Store) {
-	server, err := gapi.NewServer(config, store)
+func runGRPCGatewayServer(config utils.Config, store db.Store, revocations revocation.Checker) {
+	server, err := gapi.NewServer(config, store, revocations)
 	if err != nil {
 		log.Fatal().Err(err).Msg("can not create server")
 	}
 
+	reloadTokenKeysOnChange(server.ReloadTokenKeys)
+
 	opts := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames: true,
@@ -110,15 +306,32 @@ func runGRPCGatewayServer(config utils.Config, store db.Store) {
 			DiscardUnknown: true,
 		},
 	})
+	headerMatcher := runtime.WithIncomingHeaderMatcher(gapi.IncomingHeaderMatcher)
 
-	grpcMux := runtime.NewServeMux(opts)
+	grpcMux := runtime.NewServeMux(opts, headerMatcher)
 	ctx := context.Background()
-	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
+	// 请求已经由HttpLogger记录，这里只需要鉴权拦截器
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.UnaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(server.StreamAuthInterceptor),
+	)
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	pb.RegisterAdminServiceServer(grpcServer, gapi.NewAdminServer(server))
+	gatewayConn, err := gapi.DialGateway(grpcServer)
+	if err != nil {
+		log.Fatal().Err(err).Msg("can not dial gateway connection")
+	}
+	err = pb.RegisterSimpleBankHandlerClient(ctx, grpcMux, pb.NewSimpleBankClient(gatewayConn))
 	if err != nil {
-		log.Fatal().Err(err).Msg("can not register handler server")
+		log.Fatal().Err(err).Msg("can not register handler client")
+	}
+	err = pb.RegisterAdminServiceHandlerClient(ctx, grpcMux, pb.NewAdminServiceClient(gatewayConn))
+	if err != nil {
+		log.Fatal().Err(err).Msg("can not register admin handler client")
 	}
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.Handle("/.well-known/jwks.json", server.JWKSHandler())
 	statikFS, err := fs.New()
 	if err != nil {
 		log.Fatal().Err(err).Msg("can not create statik fs")
@@ -130,7 +343,7 @@ func runGRPCGatewayServer(config utils.Config, store db.Store) {
 	listener, err := net.Listen("tcp", config.HTTPServerAddress)
 	log.Info().Msgf("start gataway server at %s", listener.Addr().String())
 	handler := gapi.HttpLogger(mux)
-	
+
 	err = http.Serve(listener, handler)
 	if err != nil {
 		log.Fatal().Err(err).Msg("can not start http server")
@@ -0,0 +1,87 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func TestPageToken(t *testing.T) {
+	codec, err := NewPageTokenCodec(utils.RandomString(32))
+	require.NoError(t, err)
+
+	cursor := Cursor{
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		ID:        utils.RandomInt63(1, 1000),
+	}
+	pageToken, err := codec.Encode(cursor, "list_accounts:alice")
+	require.NoError(t, err)
+	require.NotEmpty(t, pageToken)
+
+	decoded, err := codec.Decode(pageToken, "list_accounts:alice")
+	require.NoError(t, err)
+	require.Equal(t, cursor.ID, decoded.ID)
+	require.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+}
+
+func TestPageTokenWrongScope(t *testing.T) {
+	codec, err := NewPageTokenCodec(utils.RandomString(32))
+	require.NoError(t, err)
+
+	pageToken, err := codec.Encode(Cursor{CreatedAt: time.Now(), ID: 1}, "list_accounts:alice")
+	require.NoError(t, err)
+
+	_, err = codec.Decode(pageToken, "list_accounts:bob")
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestPageTokenWrongKey(t *testing.T) {
+	codec1, err := NewPageTokenCodec(utils.RandomString(32))
+	require.NoError(t, err)
+	codec2, err := NewPageTokenCodec(utils.RandomString(32))
+	require.NoError(t, err)
+
+	pageToken, err := codec1.Encode(Cursor{CreatedAt: time.Now(), ID: 1}, "list_accounts:alice")
+	require.NoError(t, err)
+
+	_, err = codec2.Decode(pageToken, "list_accounts:alice")
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestPageTokenMalformed(t *testing.T) {
+	codec, err := NewPageTokenCodec(utils.RandomString(32))
+	require.NoError(t, err)
+
+	for _, pageToken := range []string{"", "abc", "abc.def", "!!!.???"} {
+		_, err = codec.Decode(pageToken, "list_accounts:alice")
+		require.ErrorIs(t, err, ErrInvalidPageToken)
+	}
+}
+
+func TestPageTokenShortKey(t *testing.T) {
+	codec, err := NewPageTokenCodec(utils.RandomString(minSecretKeySize - 1))
+	require.Error(t, err)
+	require.Nil(t, codec)
+}
+
+func TestKeyset(t *testing.T) {
+	codec, err := NewPageTokenCodec(utils.RandomString(32))
+	require.NoError(t, err)
+
+	afterCreatedAt, afterID, err := codec.Keyset("", "list_accounts:alice")
+	require.NoError(t, err)
+	require.False(t, afterCreatedAt.Valid)
+	require.Zero(t, afterID)
+
+	cursor := Cursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: 42}
+	pageToken, err := codec.Encode(cursor, "list_accounts:alice")
+	require.NoError(t, err)
+
+	afterCreatedAt, afterID, err = codec.Keyset(pageToken, "list_accounts:alice")
+	require.NoError(t, err)
+	require.True(t, afterCreatedAt.Valid)
+	require.True(t, cursor.CreatedAt.Equal(afterCreatedAt.Time))
+	require.Equal(t, cursor.ID, afterID)
+}
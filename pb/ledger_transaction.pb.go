@@ -0,0 +1,163 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: ledger_transaction.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LedgerTransaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	AccountId     int64                  `protobuf:"varint,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LedgerTransaction) Reset() {
+	*x = LedgerTransaction{}
+	mi := &file_ledger_transaction_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LedgerTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerTransaction) ProtoMessage() {}
+
+func (x *LedgerTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_ledger_transaction_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerTransaction.ProtoReflect.Descriptor instead.
+func (*LedgerTransaction) Descriptor() ([]byte, []int) {
+	return file_ledger_transaction_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LedgerTransaction) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *LedgerTransaction) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *LedgerTransaction) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *LedgerTransaction) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *LedgerTransaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_ledger_transaction_proto protoreflect.FileDescriptor
+
+const file_ledger_transaction_proto_rawDesc = "" +
+	"\n" +
+	"\x18ledger_transaction.proto\x12\x02pb\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa9\x01\n" +
+	"\x11LedgerTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\x03R\taccountId\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB&Z$github.com/zjr71163356/simplebank/pbb\x06proto3"
+
+var (
+	file_ledger_transaction_proto_rawDescOnce sync.Once
+	file_ledger_transaction_proto_rawDescData []byte
+)
+
+func file_ledger_transaction_proto_rawDescGZIP() []byte {
+	file_ledger_transaction_proto_rawDescOnce.Do(func() {
+		file_ledger_transaction_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ledger_transaction_proto_rawDesc), len(file_ledger_transaction_proto_rawDesc)))
+	})
+	return file_ledger_transaction_proto_rawDescData
+}
+
+var file_ledger_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_ledger_transaction_proto_goTypes = []any{
+	(*LedgerTransaction)(nil),     // 0: pb.LedgerTransaction
+	(*timestamppb.Timestamp)(nil), // 1: google.protobuf.Timestamp
+}
+var file_ledger_transaction_proto_depIdxs = []int32{
+	1, // 0: pb.LedgerTransaction.created_at:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_ledger_transaction_proto_init() }
+func file_ledger_transaction_proto_init() {
+	if File_ledger_transaction_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ledger_transaction_proto_rawDesc), len(file_ledger_transaction_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_ledger_transaction_proto_goTypes,
+		DependencyIndexes: file_ledger_transaction_proto_depIdxs,
+		MessageInfos:      file_ledger_transaction_proto_msgTypes,
+	}.Build()
+	File_ledger_transaction_proto = out.File
+	file_ledger_transaction_proto_goTypes = nil
+	file_ledger_transaction_proto_depIdxs = nil
+}
@@ -0,0 +1,160 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_force_logout.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ForceLogoutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForceLogoutRequest) Reset() {
+	*x = ForceLogoutRequest{}
+	mi := &file_rpc_force_logout_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForceLogoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForceLogoutRequest) ProtoMessage() {}
+
+func (x *ForceLogoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_force_logout_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForceLogoutRequest.ProtoReflect.Descriptor instead.
+func (*ForceLogoutRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_force_logout_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ForceLogoutRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+type ForceLogoutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForceLogoutResponse) Reset() {
+	*x = ForceLogoutResponse{}
+	mi := &file_rpc_force_logout_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForceLogoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForceLogoutResponse) ProtoMessage() {}
+
+func (x *ForceLogoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_force_logout_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForceLogoutResponse.ProtoReflect.Descriptor instead.
+func (*ForceLogoutResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_force_logout_proto_rawDescGZIP(), []int{1}
+}
+
+var File_rpc_force_logout_proto protoreflect.FileDescriptor
+
+const file_rpc_force_logout_proto_rawDesc = "" +
+	"\n" +
+	"\x16rpc_force_logout.proto\x12\x02pb\"0\n" +
+	"\x12ForceLogoutRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\"\x15\n" +
+	"\x13ForceLogoutResponseB&Z$github.com/zjr71163356/simplebank/pbb\x06proto3"
+
+var (
+	file_rpc_force_logout_proto_rawDescOnce sync.Once
+	file_rpc_force_logout_proto_rawDescData []byte
+)
+
+func file_rpc_force_logout_proto_rawDescGZIP() []byte {
+	file_rpc_force_logout_proto_rawDescOnce.Do(func() {
+		file_rpc_force_logout_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_force_logout_proto_rawDesc), len(file_rpc_force_logout_proto_rawDesc)))
+	})
+	return file_rpc_force_logout_proto_rawDescData
+}
+
+var file_rpc_force_logout_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_force_logout_proto_goTypes = []any{
+	(*ForceLogoutRequest)(nil),  // 0: pb.ForceLogoutRequest
+	(*ForceLogoutResponse)(nil), // 1: pb.ForceLogoutResponse
+}
+var file_rpc_force_logout_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rpc_force_logout_proto_init() }
+func file_rpc_force_logout_proto_init() {
+	if File_rpc_force_logout_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_force_logout_proto_rawDesc), len(file_rpc_force_logout_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_force_logout_proto_goTypes,
+		DependencyIndexes: file_rpc_force_logout_proto_depIdxs,
+		MessageInfos:      file_rpc_force_logout_proto_msgTypes,
+	}.Build()
+	File_rpc_force_logout_proto = out.File
+	file_rpc_force_logout_proto_goTypes = nil
+	file_rpc_force_logout_proto_depIdxs = nil
+}
@@ -0,0 +1,101 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: service_admin.proto
+
+package pb
+
+import (
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_service_admin_proto protoreflect.FileDescriptor
+
+const file_service_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x13service_admin.proto\x12\x02pb\x1a\x14rpc_list_users.proto\x1a\x12rpc_get_user.proto\x1a\x16rpc_disable_user.proto\x1a\x15rpc_enable_user.proto\x1a\x16rpc_force_logout.proto\x1a\x1brpc_list_audit_events.proto\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto2\xf6\b\n" +
+	"\fAdminService\x12\x98\x01\n" +
+	"\tListUsers\x12\x14.pb.ListUsersRequest\x1a\x15.pb.ListUsersResponse\"^\x92A?\x12\n" +
+	"List users\x1a1Use this API to search users by username or email\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/admin/list_users\x12\xa9\x01\n" +
+	"\aGetUser\x12\x12.pb.GetUserRequest\x1a\x13.pb.GetUserResponse\"u\x92AM\x12\bGet user\x1aAUse this API to get a user with the number of its active sessions\x82\xd3\xe4\x93\x02\x1f\x12\x1d/v1/admin/get_user/{username}\x12\xb3\x01\n" +
+	"\vDisableUser\x12\x16.pb.DisableUserRequest\x1a\x17.pb.DisableUserResponse\"s\x92AO\x12\fDisable user\x1a?Use this API to lock a user out, signing it out of all sessions\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/admin/disable_user\x12\x9f\x01\n" +
+	"\n" +
+	"EnableUser\x12\x15.pb.EnableUserRequest\x1a\x16.pb.EnableUserResponse\"b\x92A?\x12\vEnable user\x1a0Use this API to let a disabled user log in again\x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/admin/enable_user\x12\xc7\x01\n" +
+	"\vForceLogout\x12\x16.pb.ForceLogoutRequest\x1a\x17.pb.ForceLogoutResponse\"\x86\x01\x92Ab\x12\fForce logout\x1aRUse this API to sign a user out of all sessions, for example after a password leak\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/admin/force_logout\x12\xfc\x01\n" +
+	"\x0fListAuditEvents\x12\x1a.pb.ListAuditEventsRequest\x1a\x1b.pb.ListAuditEventsResponse\"\xaf\x01\x92A\x88\x01\x12\x11List audit events\x1asUse this API to find who changed a user, an account or a transfer, filtered by actor, action, target and time range\x82\xd3\xe4\x93\x02\x1d\x12\x1b/v1/admin/list_audit_eventsB&Z$github.com/zjr71163356/simplebank/pbb\x06proto3"
+
+var file_service_admin_proto_goTypes = []any{
+	(*ListUsersRequest)(nil),        // 0: pb.ListUsersRequest
+	(*GetUserRequest)(nil),          // 1: pb.GetUserRequest
+	(*DisableUserRequest)(nil),      // 2: pb.DisableUserRequest
+	(*EnableUserRequest)(nil),       // 3: pb.EnableUserRequest
+	(*ForceLogoutRequest)(nil),      // 4: pb.ForceLogoutRequest
+	(*ListAuditEventsRequest)(nil),  // 5: pb.ListAuditEventsRequest
+	(*ListUsersResponse)(nil),       // 6: pb.ListUsersResponse
+	(*GetUserResponse)(nil),         // 7: pb.GetUserResponse
+	(*DisableUserResponse)(nil),     // 8: pb.DisableUserResponse
+	(*EnableUserResponse)(nil),      // 9: pb.EnableUserResponse
+	(*ForceLogoutResponse)(nil),     // 10: pb.ForceLogoutResponse
+	(*ListAuditEventsResponse)(nil), // 11: pb.ListAuditEventsResponse
+}
+var file_service_admin_proto_depIdxs = []int32{
+	0,  // 0: pb.AdminService.ListUsers:input_type -> pb.ListUsersRequest
+	1,  // 1: pb.AdminService.GetUser:input_type -> pb.GetUserRequest
+	2,  // 2: pb.AdminService.DisableUser:input_type -> pb.DisableUserRequest
+	3,  // 3: pb.AdminService.EnableUser:input_type -> pb.EnableUserRequest
+	4,  // 4: pb.AdminService.ForceLogout:input_type -> pb.ForceLogoutRequest
+	5,  // 5: pb.AdminService.ListAuditEvents:input_type -> pb.ListAuditEventsRequest
+	6,  // 6: pb.AdminService.ListUsers:output_type -> pb.ListUsersResponse
+	7,  // 7: pb.AdminService.GetUser:output_type -> pb.GetUserResponse
+	8,  // 8: pb.AdminService.DisableUser:output_type -> pb.DisableUserResponse
+	9,  // 9: pb.AdminService.EnableUser:output_type -> pb.EnableUserResponse
+	10, // 10: pb.AdminService.ForceLogout:output_type -> pb.ForceLogoutResponse
+	11, // 11: pb.AdminService.ListAuditEvents:output_type -> pb.ListAuditEventsResponse
+	6,  // [6:12] is the sub-list for method output_type
+	0,  // [0:6] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_service_admin_proto_init() }
+func file_service_admin_proto_init() {
+	if File_service_admin_proto != nil {
+		return
+	}
+	file_rpc_list_users_proto_init()
+	file_rpc_get_user_proto_init()
+	file_rpc_disable_user_proto_init()
+	file_rpc_enable_user_proto_init()
+	file_rpc_force_logout_proto_init()
+	file_rpc_list_audit_events_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_admin_proto_rawDesc), len(file_service_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_service_admin_proto_goTypes,
+		DependencyIndexes: file_service_admin_proto_depIdxs,
+	}.Build()
+	File_service_admin_proto = out.File
+	file_service_admin_proto_goTypes = nil
+	file_service_admin_proto_depIdxs = nil
+}
@@ -7,6 +7,7 @@
 package pb
 
 import (
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -25,35 +26,113 @@ var File_service_simple_bank_proto protoreflect.FileDescriptor
 
 const file_service_simple_bank_proto_rawDesc = "" +
 	"\n" +
-	"\x19service_simple_bank.proto\x12\x02pb\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x15rpc_update_user.proto\x1a\x1cgoogle/api/annotations.proto2\x93\x02\n" +
+	"\x19service_simple_bank.proto\x12\x02pb\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x15rpc_update_user.proto\x1a\x1crpc_renew_access_token.proto\x1a\x10rpc_logout.proto\x1a\x17rpc_list_sessions.proto\x1a\x18rpc_revoke_session.proto\x1a\x1drpc_revoke_all_sessions.proto\x1a\x18rpc_create_account.proto\x1a\x15rpc_get_account.proto\x1a\x17rpc_list_accounts.proto\x1a\x19rpc_create_transfer.proto\x1a\x17rpc_get_statement.proto\x1a\x18rpc_list_transfers.proto\x1a\x16rpc_list_entries.proto\x1a\x11rpc_deposit.proto\x1a\x12rpc_withdraw.proto\x1a\x1crpc_get_transfer_quote.proto\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto2\xb0\x1c\n" +
 	"\n" +
-	"SimpleBank\x12W\n" +
+	"SimpleBank\x12\x8e\x01\n" +
 	"\n" +
-	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12S\n" +
-	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12W\n" +
+	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"Q\x92A4\x12\x0fCreate new user\x1a!Use this API to create a new user\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12\xa3\x01\n" +
+	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"i\x92AM\x12\n" +
+	"Login user\x1a?Use this API to login user and get access token & refresh token\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12\xfc\x01\n" +
+	"\x10RenewAccessToken\x12\x1b.pb.RenewAccessTokenRequest\x1a\x1c.pb.RenewAccessTokenResponse\"\xac\x01\x92A\x87\x01\x12\x12Renew access token\x1aqUse this API to get a new access token with a refresh token. The refresh token is rotated and can't be used again\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/renew_access_token\x12\x95\x01\n" +
+	"\x06Logout\x12\x11.pb.LogoutRequest\x1a\x12.pb.LogoutResponse\"d\x92AL\x12\x06Logout\x1aBUse this API to log out by revoking the session of a refresh token\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
+	"/v1/logout\x12\xc2\x01\n" +
+	"\fListSessions\x12\x17.pb.ListSessionsRequest\x1a\x18.pb.ListSessionsResponse\"\x7f\x92Ac\x12\rList sessions\x1aRUse this API to list the active sessions (signed in devices) of the logged in user\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/list_sessions\x12\xba\x01\n" +
+	"\rRevokeSession\x12\x18.pb.RevokeSessionRequest\x1a\x19.pb.RevokeSessionResponse\"t\x92AT\x12\x0eRevoke session\x1aBUse this API to sign out one of the sessions of the logged in user\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/revoke_session\x12\xc9\x01\n" +
+	"\x11RevokeAllSessions\x12\x1c.pb.RevokeAllSessionsRequest\x1a\x1d.pb.RevokeAllSessionsResponse\"w\x92AR\x12\x13Revoke all sessions\x1a;Use this API to sign out all sessions of the logged in user\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/revoke_all_sessions\x12\x84\x01\n" +
 	"\n" +
-	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_userB&Z$github.com/zjr71163356/simplebank/pbb\x06proto3"
+	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"G\x92A*\x12\vUpdate user\x1a\x1bUse this API to update user\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_user\x12\xb7\x01\n" +
+	"\rCreateAccount\x12\x18.pb.CreateAccountRequest\x1a\x19.pb.CreateAccountResponse\"q\x92AQ\x12\x12Create new account\x1a;Use this API to create a new account for the logged in user\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/create_account\x12\xa5\x01\n" +
+	"\n" +
+	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"h\x92AI\x12\vGet account\x1a:Use this API to get an account owned by the logged in user\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/get_account/{id}\x12\xa9\x01\n" +
+	"\fListAccounts\x12\x17.pb.ListAccountsRequest\x1a\x18.pb.ListAccountsResponse\"f\x92AJ\x12\rList accounts\x1a9Use this API to list accounts owned by the logged in user\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/list_accounts\x12\xa1\x02\n" +
+	"\x0eCreateTransfer\x12\x19.pb.CreateTransferRequest\x1a\x1a.pb.CreateTransferResponse\"\xd7\x01\x92A\xb5\x01\x12\x0fCreate transfer\x1a\xa1\x01Use this API to transfer money from an account owned by the logged in user to another account. Transfers to an account in another currency need the id of a quote\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/create_transfer\x12\xf9\x01\n" +
+	"\x10GetTransferQuote\x12\x1b.pb.GetTransferQuoteRequest\x1a\x1c.pb.GetTransferQuoteResponse\"\xa9\x01\x92A\x84\x01\x12\x12Get transfer quote\x1anUse this API to lock the exchange rate of a transfer to an account in another currency until the quote expires\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/get_transfer_quote\x12\x90\x02\n" +
+	"\fGetStatement\x12\x17.pb.GetStatementRequest\x1a\x18.pb.GetStatementResponse\"\xcc\x01\x92A\xa2\x01\x12\x15Get account statement\x1a\x88\x01Use this API to get the entries of an account owned by the logged in user within a time range, with the running balance after each entry\x82\xd3\xe4\x93\x02 \x12\x1e/v1/get_statement/{account_id}\x12\xfa\x01\n" +
+	"\rListTransfers\x12\x18.pb.ListTransfersRequest\x1a\x19.pb.ListTransfersResponse\"\xb3\x01\x92A\x95\x01\x12\x0eList transfers\x1a\x82\x01Use this API to list transfers of accounts owned by the logged in user, filtered by direction, counterparty, amount and time range\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/list_transfers\x12\xbe\x01\n" +
+	"\vListEntries\x12\x16.pb.ListEntriesRequest\x1a\x17.pb.ListEntriesResponse\"~\x92AV\x12\fList entries\x1aFUse this API to list entries of an account owned by the logged in user\x82\xd3\xe4\x93\x02\x1f\x12\x1d/v1/list_entries/{account_id}\x12\xbe\x01\n" +
+	"\aDeposit\x12\x12.pb.DepositRequest\x1a\x13.pb.DepositResponse\"\x89\x01\x92Ap\x12\aDeposit\x1aeUse this API to deposit cash into an account, funded by the cash account of the bank. Bank staff only\x82\xd3\xe4\x93\x02\x10:\x01*\"\v/v1/deposit\x12\xbe\x01\n" +
+	"\bWithdraw\x12\x13.pb.WithdrawRequest\x1a\x14.pb.WithdrawResponse\"\x86\x01\x92Al\x12\bWithdraw\x1a`Use this API to withdraw cash from an account into the cash account of the bank. Bank staff only\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/withdrawB&Z$github.com/zjr71163356/simplebank/pbb\x06proto3"
 
 var file_service_simple_bank_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),  // 0: pb.CreateUserRequest
-	(*LoginUserRequest)(nil),   // 1: pb.LoginUserRequest
-	(*UpdateUserRequest)(nil),  // 2: pb.UpdateUserRequest
-	(*CreateUserResponse)(nil), // 3: pb.CreateUserResponse
-	(*LoginUserResponse)(nil),  // 4: pb.LoginUserResponse
-	(*UpdateUserResponse)(nil), // 5: pb.UpdateUserResponse
+	(*CreateUserRequest)(nil),         // 0: pb.CreateUserRequest
+	(*LoginUserRequest)(nil),          // 1: pb.LoginUserRequest
+	(*RenewAccessTokenRequest)(nil),   // 2: pb.RenewAccessTokenRequest
+	(*LogoutRequest)(nil),             // 3: pb.LogoutRequest
+	(*ListSessionsRequest)(nil),       // 4: pb.ListSessionsRequest
+	(*RevokeSessionRequest)(nil),      // 5: pb.RevokeSessionRequest
+	(*RevokeAllSessionsRequest)(nil),  // 6: pb.RevokeAllSessionsRequest
+	(*UpdateUserRequest)(nil),         // 7: pb.UpdateUserRequest
+	(*CreateAccountRequest)(nil),      // 8: pb.CreateAccountRequest
+	(*GetAccountRequest)(nil),         // 9: pb.GetAccountRequest
+	(*ListAccountsRequest)(nil),       // 10: pb.ListAccountsRequest
+	(*CreateTransferRequest)(nil),     // 11: pb.CreateTransferRequest
+	(*GetTransferQuoteRequest)(nil),   // 12: pb.GetTransferQuoteRequest
+	(*GetStatementRequest)(nil),       // 13: pb.GetStatementRequest
+	(*ListTransfersRequest)(nil),      // 14: pb.ListTransfersRequest
+	(*ListEntriesRequest)(nil),        // 15: pb.ListEntriesRequest
+	(*DepositRequest)(nil),            // 16: pb.DepositRequest
+	(*WithdrawRequest)(nil),           // 17: pb.WithdrawRequest
+	(*CreateUserResponse)(nil),        // 18: pb.CreateUserResponse
+	(*LoginUserResponse)(nil),         // 19: pb.LoginUserResponse
+	(*RenewAccessTokenResponse)(nil),  // 20: pb.RenewAccessTokenResponse
+	(*LogoutResponse)(nil),            // 21: pb.LogoutResponse
+	(*ListSessionsResponse)(nil),      // 22: pb.ListSessionsResponse
+	(*RevokeSessionResponse)(nil),     // 23: pb.RevokeSessionResponse
+	(*RevokeAllSessionsResponse)(nil), // 24: pb.RevokeAllSessionsResponse
+	(*UpdateUserResponse)(nil),        // 25: pb.UpdateUserResponse
+	(*CreateAccountResponse)(nil),     // 26: pb.CreateAccountResponse
+	(*GetAccountResponse)(nil),        // 27: pb.GetAccountResponse
+	(*ListAccountsResponse)(nil),      // 28: pb.ListAccountsResponse
+	(*CreateTransferResponse)(nil),    // 29: pb.CreateTransferResponse
+	(*GetTransferQuoteResponse)(nil),  // 30: pb.GetTransferQuoteResponse
+	(*GetStatementResponse)(nil),      // 31: pb.GetStatementResponse
+	(*ListTransfersResponse)(nil),     // 32: pb.ListTransfersResponse
+	(*ListEntriesResponse)(nil),       // 33: pb.ListEntriesResponse
+	(*DepositResponse)(nil),           // 34: pb.DepositResponse
+	(*WithdrawResponse)(nil),          // 35: pb.WithdrawResponse
 }
 var file_service_simple_bank_proto_depIdxs = []int32{
-	0, // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
-	1, // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
-	2, // 2: pb.SimpleBank.UpdateUser:input_type -> pb.UpdateUserRequest
-	3, // 3: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
-	4, // 4: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
-	5, // 5: pb.SimpleBank.UpdateUser:output_type -> pb.UpdateUserResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
+	1,  // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
+	2,  // 2: pb.SimpleBank.RenewAccessToken:input_type -> pb.RenewAccessTokenRequest
+	3,  // 3: pb.SimpleBank.Logout:input_type -> pb.LogoutRequest
+	4,  // 4: pb.SimpleBank.ListSessions:input_type -> pb.ListSessionsRequest
+	5,  // 5: pb.SimpleBank.RevokeSession:input_type -> pb.RevokeSessionRequest
+	6,  // 6: pb.SimpleBank.RevokeAllSessions:input_type -> pb.RevokeAllSessionsRequest
+	7,  // 7: pb.SimpleBank.UpdateUser:input_type -> pb.UpdateUserRequest
+	8,  // 8: pb.SimpleBank.CreateAccount:input_type -> pb.CreateAccountRequest
+	9,  // 9: pb.SimpleBank.GetAccount:input_type -> pb.GetAccountRequest
+	10, // 10: pb.SimpleBank.ListAccounts:input_type -> pb.ListAccountsRequest
+	11, // 11: pb.SimpleBank.CreateTransfer:input_type -> pb.CreateTransferRequest
+	12, // 12: pb.SimpleBank.GetTransferQuote:input_type -> pb.GetTransferQuoteRequest
+	13, // 13: pb.SimpleBank.GetStatement:input_type -> pb.GetStatementRequest
+	14, // 14: pb.SimpleBank.ListTransfers:input_type -> pb.ListTransfersRequest
+	15, // 15: pb.SimpleBank.ListEntries:input_type -> pb.ListEntriesRequest
+	16, // 16: pb.SimpleBank.Deposit:input_type -> pb.DepositRequest
+	17, // 17: pb.SimpleBank.Withdraw:input_type -> pb.WithdrawRequest
+	18, // 18: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
+	19, // 19: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
+	20, // 20: pb.SimpleBank.RenewAccessToken:output_type -> pb.RenewAccessTokenResponse
+	21, // 21: pb.SimpleBank.Logout:output_type -> pb.LogoutResponse
+	22, // 22: pb.SimpleBank.ListSessions:output_type -> pb.ListSessionsResponse
+	23, // 23: pb.SimpleBank.RevokeSession:output_type -> pb.RevokeSessionResponse
+	24, // 24: pb.SimpleBank.RevokeAllSessions:output_type -> pb.RevokeAllSessionsResponse
+	25, // 25: pb.SimpleBank.UpdateUser:output_type -> pb.UpdateUserResponse
+	26, // 26: pb.SimpleBank.CreateAccount:output_type -> pb.CreateAccountResponse
+	27, // 27: pb.SimpleBank.GetAccount:output_type -> pb.GetAccountResponse
+	28, // 28: pb.SimpleBank.ListAccounts:output_type -> pb.ListAccountsResponse
+	29, // 29: pb.SimpleBank.CreateTransfer:output_type -> pb.CreateTransferResponse
+	30, // 30: pb.SimpleBank.GetTransferQuote:output_type -> pb.GetTransferQuoteResponse
+	31, // 31: pb.SimpleBank.GetStatement:output_type -> pb.GetStatementResponse
+	32, // 32: pb.SimpleBank.ListTransfers:output_type -> pb.ListTransfersResponse
+	33, // 33: pb.SimpleBank.ListEntries:output_type -> pb.ListEntriesResponse
+	34, // 34: pb.SimpleBank.Deposit:output_type -> pb.DepositResponse
+	35, // 35: pb.SimpleBank.Withdraw:output_type -> pb.WithdrawResponse
+	18, // [18:36] is the sub-list for method output_type
+	0,  // [0:18] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_service_simple_bank_proto_init() }
@@ -64,6 +143,21 @@ func file_service_simple_bank_proto_init() {
 	file_rpc_create_user_proto_init()
 	file_rpc_login_user_proto_init()
 	file_rpc_update_user_proto_init()
+	file_rpc_renew_access_token_proto_init()
+	file_rpc_logout_proto_init()
+	file_rpc_list_sessions_proto_init()
+	file_rpc_revoke_session_proto_init()
+	file_rpc_revoke_all_sessions_proto_init()
+	file_rpc_create_account_proto_init()
+	file_rpc_get_account_proto_init()
+	file_rpc_list_accounts_proto_init()
+	file_rpc_create_transfer_proto_init()
+	file_rpc_get_statement_proto_init()
+	file_rpc_list_transfers_proto_init()
+	file_rpc_list_entries_proto_init()
+	file_rpc_deposit_proto_init()
+	file_rpc_withdraw_proto_init()
+	file_rpc_get_transfer_quote_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -0,0 +1,199 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: transfer_quote.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TransferQuote struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FromAccountId   int64                  `protobuf:"varint,2,opt,name=from_account_id,json=fromAccountId,proto3" json:"from_account_id,omitempty"`
+	ToAccountId     int64                  `protobuf:"varint,3,opt,name=to_account_id,json=toAccountId,proto3" json:"to_account_id,omitempty"`
+	FromCurrency    string                 `protobuf:"bytes,4,opt,name=from_currency,json=fromCurrency,proto3" json:"from_currency,omitempty"`
+	ToCurrency      string                 `protobuf:"bytes,5,opt,name=to_currency,json=toCurrency,proto3" json:"to_currency,omitempty"`
+	Amount          int64                  `protobuf:"varint,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	ConvertedAmount int64                  `protobuf:"varint,7,opt,name=converted_amount,json=convertedAmount,proto3" json:"converted_amount,omitempty"`
+	Rate            string                 `protobuf:"bytes,8,opt,name=rate,proto3" json:"rate,omitempty"`
+	ExpiresAt       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransferQuote) Reset() {
+	*x = TransferQuote{}
+	mi := &file_transfer_quote_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferQuote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferQuote) ProtoMessage() {}
+
+func (x *TransferQuote) ProtoReflect() protoreflect.Message {
+	mi := &file_transfer_quote_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferQuote.ProtoReflect.Descriptor instead.
+func (*TransferQuote) Descriptor() ([]byte, []int) {
+	return file_transfer_quote_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TransferQuote) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransferQuote) GetFromAccountId() int64 {
+	if x != nil {
+		return x.FromAccountId
+	}
+	return 0
+}
+
+func (x *TransferQuote) GetToAccountId() int64 {
+	if x != nil {
+		return x.ToAccountId
+	}
+	return 0
+}
+
+func (x *TransferQuote) GetFromCurrency() string {
+	if x != nil {
+		return x.FromCurrency
+	}
+	return ""
+}
+
+func (x *TransferQuote) GetToCurrency() string {
+	if x != nil {
+		return x.ToCurrency
+	}
+	return ""
+}
+
+func (x *TransferQuote) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *TransferQuote) GetConvertedAmount() int64 {
+	if x != nil {
+		return x.ConvertedAmount
+	}
+	return 0
+}
+
+func (x *TransferQuote) GetRate() string {
+	if x != nil {
+		return x.Rate
+	}
+	return ""
+}
+
+func (x *TransferQuote) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+var File_transfer_quote_proto protoreflect.FileDescriptor
+
+const file_transfer_quote_proto_rawDesc = "" +
+	"\n" +
+	"\x14transfer_quote.proto\x12\x02pb\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc3\x02\n" +
+	"\rTransferQuote\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
+	"\x0ffrom_account_id\x18\x02 \x01(\x03R\rfromAccountId\x12\"\n" +
+	"\rto_account_id\x18\x03 \x01(\x03R\vtoAccountId\x12#\n" +
+	"\rfrom_currency\x18\x04 \x01(\tR\ffromCurrency\x12\x1f\n" +
+	"\vto_currency\x18\x05 \x01(\tR\n" +
+	"toCurrency\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x03R\x06amount\x12)\n" +
+	"\x10converted_amount\x18\a \x01(\x03R\x0fconvertedAmount\x12\x12\n" +
+	"\x04rate\x18\b \x01(\tR\x04rate\x129\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAtB&Z$github.com/zjr71163356/simplebank/pbb\x06proto3"
+
+var (
+	file_transfer_quote_proto_rawDescOnce sync.Once
+	file_transfer_quote_proto_rawDescData []byte
+)
+
+func file_transfer_quote_proto_rawDescGZIP() []byte {
+	file_transfer_quote_proto_rawDescOnce.Do(func() {
+		file_transfer_quote_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_transfer_quote_proto_rawDesc), len(file_transfer_quote_proto_rawDesc)))
+	})
+	return file_transfer_quote_proto_rawDescData
+}
+
+var file_transfer_quote_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_transfer_quote_proto_goTypes = []any{
+	(*TransferQuote)(nil),         // 0: pb.TransferQuote
+	(*timestamppb.Timestamp)(nil), // 1: google.protobuf.Timestamp
+}
+var file_transfer_quote_proto_depIdxs = []int32{
+	1, // 0: pb.TransferQuote.expires_at:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_transfer_quote_proto_init() }
+func file_transfer_quote_proto_init() {
+	if File_transfer_quote_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transfer_quote_proto_rawDesc), len(file_transfer_quote_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_transfer_quote_proto_goTypes,
+		DependencyIndexes: file_transfer_quote_proto_depIdxs,
+		MessageInfos:      file_transfer_quote_proto_msgTypes,
+	}.Build()
+	File_transfer_quote_proto = out.File
+	file_transfer_quote_proto_goTypes = nil
+	file_transfer_quote_proto_depIdxs = nil
+}
@@ -0,0 +1,130 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zjr71163356/simplebank/token"
+)
+
+const (
+	RoleDepositor = "depositor"
+	RoleBanker    = "banker"
+	RoleAdmin     = "admin"
+)
+
+// Scopes a token can be restricted to. Tokens without scopes, such as the
+// ones issued at login, are not restricted.
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeAccountsWrite  = "accounts:write"
+	ScopeTransfersWrite = "transfers:write"
+	ScopeLedgerWrite    = "ledger:write"
+	ScopeUserWrite      = "user:write"
+	ScopeSessions       = "sessions"
+	ScopeUsersAdmin     = "users:admin"
+	ScopeAuditRead      = "audit:read"
+)
+
+var (
+	AllRoles   = []string{RoleDepositor, RoleBanker, RoleAdmin}
+	StaffRoles = []string{RoleBanker, RoleAdmin}
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrScopeNotAllowed  = errors.New("scope is not allowed")
+)
+
+// RoleScopes are the scopes each role may request for a restricted token
+var RoleScopes = map[string][]string{
+	RoleDepositor: {ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersWrite, ScopeUserWrite, ScopeSessions},
+	RoleBanker:    {ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersWrite, ScopeUserWrite, ScopeSessions, ScopeLedgerWrite},
+	RoleAdmin:     {ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersWrite, ScopeUserWrite, ScopeSessions, ScopeLedgerWrite, ScopeUsersAdmin, ScopeAuditRead},
+}
+
+// Rule is the permission of one endpoint
+type Rule struct {
+	// Roles may call the endpoint
+	Roles []string
+	// Scope must be granted to tokens restricted to scopes
+	Scope string
+	// AnyOwnerRoles may act on accounts owned by other users, all other
+	// roles only on their own
+	AnyOwnerRoles []string
+}
+
+// Table maps an endpoint, a gRPC method or an HTTP route, to its rule
+type Table map[string]Rule
+
+func IsValidRole(role string) bool {
+	return slices.Contains(AllRoles, role)
+}
+
+// Authorize checks that the token may call the endpoint. Endpoints missing
+// from the table are denied, so that a new endpoint is never open by mistake.
+func (table Table) Authorize(endpoint string, payload *token.Payload) error {
+	rule, ok := table[endpoint]
+	if !ok {
+		return ErrPermissionDenied
+	}
+	if !hasAnyRole(payload, rule.Roles) {
+		return ErrPermissionDenied
+	}
+	if len(payload.Scopes) > 0 && !payload.HasScope(rule.Scope) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// CanAccessOwner reports whether the token may act at the endpoint on
+// resources owned by owner
+func (table Table) CanAccessOwner(endpoint string, payload *token.Payload, owner string) bool {
+	if payload.Username == owner {
+		return true
+	}
+	rule, ok := table[endpoint]
+	return ok && hasAnyRole(payload, rule.AnyOwnerRoles)
+}
+
+// GrantScopes returns the scopes of a new token for a user with role.
+// requested are the scopes asked for, current the scopes of the token being
+// renewed, empty at login. A renewal without requested scopes keeps current,
+// and a token restricted to scopes can only be narrowed, never widened to an
+// unrestricted one.
+func GrantScopes(role string, requested []string, current []string) ([]string, error) {
+	if len(requested) == 0 {
+		requested = current
+	}
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	granted := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if !slices.Contains(RoleScopes[role], scope) {
+			return nil, fmt.Errorf("%w: %s", ErrScopeNotAllowed, scope)
+		}
+		if len(current) > 0 && !slices.Contains(current, scope) {
+			return nil, fmt.Errorf("%w: %s", ErrScopeNotAllowed, scope)
+		}
+		if !slices.Contains(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted, nil
+}
+
+// hasAnyRole treats tokens issued before roles were introduced as depositors
+func hasAnyRole(payload *token.Payload, roles []string) bool {
+	if len(payload.Roles) == 0 {
+		return slices.Contains(roles, RoleDepositor)
+	}
+	for _, role := range payload.Roles {
+		if slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package rbac
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/token"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+var testTable = Table{
+	"read": {
+		Roles:         AllRoles,
+		Scope:         ScopeAccountsRead,
+		AnyOwnerRoles: StaffRoles,
+	},
+	"write": {
+		Roles: AllRoles,
+		Scope: ScopeAccountsWrite,
+	},
+	"admin": {
+		Roles: []string{RoleAdmin},
+	},
+}
+
+func randomPayload(t *testing.T, options ...token.PayloadOption) *token.Payload {
+	payload, err := token.NewPayload(utils.RandomOwnerName(), time.Minute, options...)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestAuthorize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		endpoint string
+		payload  *token.Payload
+		err      error
+	}{
+		{name: "Depositor", endpoint: "write", payload: randomPayload(t, token.WithRoles(RoleDepositor))},
+		{name: "NoRolesIsDepositor", endpoint: "write", payload: randomPayload(t)},
+		{name: "Admin", endpoint: "admin", payload: randomPayload(t, token.WithRoles(RoleBanker, RoleAdmin))},
+		{name: "RoleNotAllowed", endpoint: "admin", payload: randomPayload(t, token.WithRoles(RoleBanker)), err: ErrPermissionDenied},
+		{name: "NoRolesNotAdmin", endpoint: "admin", payload: randomPayload(t), err: ErrPermissionDenied},
+		{name: "ScopeGranted", endpoint: "read", payload: randomPayload(t, token.WithScopes(ScopeAccountsRead))},
+		{name: "ScopeNotGranted", endpoint: "write", payload: randomPayload(t, token.WithScopes(ScopeAccountsRead)), err: ErrPermissionDenied},
+		{name: "UnknownEndpoint", endpoint: "unknown", payload: randomPayload(t), err: ErrPermissionDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := testTable.Authorize(tc.endpoint, tc.payload)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCanAccessOwner(t *testing.T) {
+	depositor := randomPayload(t, token.WithRoles(RoleDepositor))
+	banker := randomPayload(t, token.WithRoles(RoleBanker))
+	owner := utils.RandomOwnerName()
+
+	require.True(t, testTable.CanAccessOwner("read", depositor, depositor.Username))
+	require.False(t, testTable.CanAccessOwner("read", depositor, owner))
+	require.True(t, testTable.CanAccessOwner("read", banker, owner))
+	require.False(t, testTable.CanAccessOwner("write", banker, owner))
+	require.False(t, testTable.CanAccessOwner("unknown", banker, owner))
+}
+
+func TestIsValidRole(t *testing.T) {
+	for _, role := range AllRoles {
+		require.True(t, IsValidRole(role))
+	}
+	require.False(t, IsValidRole("root"))
+}
+
+func TestGrantScopes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		role      string
+		requested []string
+		current   []string
+		expected  []string
+		err       error
+	}{
+		{name: "Unrestricted", role: RoleDepositor},
+		{name: "ReadOnly", role: RoleDepositor, requested: []string{ScopeAccountsRead}, expected: []string{ScopeAccountsRead}},
+		{name: "Duplicates", role: RoleDepositor, requested: []string{ScopeAccountsRead, ScopeAccountsRead}, expected: []string{ScopeAccountsRead}},
+		{name: "AboveRole", role: RoleDepositor, requested: []string{ScopeLedgerWrite}, err: ErrScopeNotAllowed},
+		{name: "Unknown", role: RoleAdmin, requested: []string{"everything"}, err: ErrScopeNotAllowed},
+		{name: "StaffScope", role: RoleBanker, requested: []string{ScopeLedgerWrite}, expected: []string{ScopeLedgerWrite}},
+		{name: "RenewKeepsScopes", role: RoleDepositor, current: []string{ScopeAccountsRead}, expected: []string{ScopeAccountsRead}},
+		{name: "RenewNarrows", role: RoleBanker, requested: []string{ScopeAccountsRead}, current: []string{ScopeAccountsRead, ScopeLedgerWrite}, expected: []string{ScopeAccountsRead}},
+		{name: "RenewWidens", role: RoleDepositor, requested: []string{ScopeAccountsWrite}, current: []string{ScopeAccountsRead}, err: ErrScopeNotAllowed},
+		{name: "RenewAfterDemotion", role: RoleDepositor, current: []string{ScopeLedgerWrite}, err: ErrScopeNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scopes, err := GrantScopes(tc.role, tc.requested, tc.current)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, scopes)
+		})
+	}
+}
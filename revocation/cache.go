@@ -0,0 +1,84 @@
+package revocation
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+// lruCache is a fixed size LRU cache whose entries also expire after ttl
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List
+}
+
+func newLRUCache(size int, ttl time.Duration) *lruCache {
+	return &lruCache{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+func (cache *lruCache) get(key string) (any, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		cache.order.Remove(element)
+		delete(cache.items, key)
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (cache *lruCache) add(key string, value any) {
+	cache.addFor(key, value, cache.ttl)
+}
+
+// addFor adds an entry that expires after ttl instead of the cache ttl
+func (cache *lruCache) addFor(key string, value any, ttl time.Duration) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := cache.items[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	cache.items[key] = cache.order.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+	//超出容量时淘汰最久未使用的条目
+	for cache.order.Len() > cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (cache *lruCache) purge() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.items = make(map[string]*list.Element, cache.size)
+	cache.order.Init()
+}
@@ -0,0 +1,56 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	cache := newLRUCache(2, time.Minute)
+
+	cache.add("a", 1)
+	cache.add("b", 2)
+	value, ok := cache.get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+
+	// "b" is the least recently used entry now
+	cache.add("c", 3)
+	_, ok = cache.get("b")
+	require.False(t, ok)
+	value, ok = cache.get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+	value, ok = cache.get("c")
+	require.True(t, ok)
+	require.Equal(t, 3, value)
+
+	cache.add("a", 4)
+	value, ok = cache.get("a")
+	require.True(t, ok)
+	require.Equal(t, 4, value)
+}
+
+func TestLRUCacheExpired(t *testing.T) {
+	cache := newLRUCache(2, time.Millisecond)
+
+	cache.add("a", 1)
+	time.Sleep(5 * time.Millisecond)
+	_, ok := cache.get("a")
+	require.False(t, ok)
+	require.Zero(t, cache.order.Len())
+}
+
+func TestLRUCachePurge(t *testing.T) {
+	cache := newLRUCache(2, time.Minute)
+
+	cache.add("a", 1)
+	cache.add("b", 2)
+	cache.purge()
+	_, ok := cache.get("a")
+	require.False(t, ok)
+	_, ok = cache.get("b")
+	require.False(t, ok)
+}
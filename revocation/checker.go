@@ -0,0 +1,102 @@
+package revocation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/token"
+)
+
+const (
+	defaultCacheSize = 10000
+	defaultCacheTTL  = 30 * time.Second
+
+	// MaxNotRevokedTTL caps how long a "not revoked" answer is cached, and
+	// so how long a revocation made by another replica can go unnoticed
+	MaxNotRevokedTTL = 5 * time.Second
+)
+
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// Checker tells whether an otherwise valid token has been revoked
+type Checker interface {
+	IsRevoked(ctx context.Context, payload *token.Payload) (bool, error)
+	// Purge drops cached answers so that revocations made by this
+	// instance take effect immediately
+	Purge()
+}
+
+// CachedChecker looks revocations up in Postgres, keeping recent answers in
+// an LRU cache. One checker is shared by every server of the process, so a
+// Purge after a local revocation covers both the gRPC and the Gin path.
+// Revocations are permanent, so revoked answers are kept for the cache ttl,
+// but "not revoked" answers are kept for at most MaxNotRevokedTTL: that is the
+// longest a revocation made by another replica may be missed.
+type CachedChecker struct {
+	store         db.Querier
+	cache         *lruCache
+	notRevokedTTL time.Duration
+}
+
+func NewCachedChecker(store db.Querier, cacheSize int, cacheTTL time.Duration) *CachedChecker {
+	if cacheSize <= 0 {
+		cacheSize = defaultCacheSize
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+	return &CachedChecker{
+		store:         store,
+		cache:         newLRUCache(cacheSize, cacheTTL),
+		notRevokedTTL: min(cacheTTL, MaxNotRevokedTTL),
+	}
+}
+
+func (checker *CachedChecker) IsRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	notBefore, err := checker.notBefore(ctx, payload.Username)
+	if err != nil {
+		return false, err
+	}
+	if payload.IssuedAt.Before(notBefore) {
+		return true, nil
+	}
+
+	key := "token:" + payload.Id.String()
+	if revoked, ok := checker.cache.get(key); ok {
+		return revoked.(bool), nil
+	}
+	revoked, err := checker.store.IsTokenRevoked(ctx, payload.Id)
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		checker.cache.add(key, revoked)
+	} else {
+		checker.cache.addFor(key, revoked, checker.notRevokedTTL)
+	}
+	return revoked, nil
+}
+
+func (checker *CachedChecker) Purge() {
+	checker.cache.purge()
+}
+
+// notBefore returns the time before which every token of the user is
+// revoked, either by revoking all sessions or by changing the password,
+// or the zero time if there is none
+func (checker *CachedChecker) notBefore(ctx context.Context, username string) (time.Time, error) {
+	key := "user:" + username
+	if notBefore, ok := checker.cache.get(key); ok {
+		return notBefore.(time.Time), nil
+	}
+	notBefore, err := checker.store.GetTokenNotBefore(ctx, username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, err
+	}
+	// not before 随时可能被推后，按"未撤销"的时长缓存
+	checker.cache.addFor(key, notBefore, checker.notRevokedTTL)
+	return notBefore, nil
+}
@@ -0,0 +1,132 @@
+package revocation
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/zjr71163356/simplebank/db/mock"
+	"github.com/zjr71163356/simplebank/token"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func randomPayload(t *testing.T) *token.Payload {
+	payload, err := token.NewPayload(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestCachedChecker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	payload := randomPayload(t)
+
+	// 第二次检查应命中缓存，不再查询数据库
+	store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Eq(payload.Username)).Times(1).Return(time.Time{}, sql.ErrNoRows)
+	store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(false, nil)
+
+	checker := NewCachedChecker(store, 10, time.Minute)
+	for i := 0; i < 2; i++ {
+		revoked, err := checker.IsRevoked(context.Background(), payload)
+		require.NoError(t, err)
+		require.False(t, revoked)
+	}
+}
+
+func TestCachedCheckerRevokedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	payload := randomPayload(t)
+
+	store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows)
+	store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(true, nil)
+
+	checker := NewCachedChecker(store, 10, time.Minute)
+	revoked, err := checker.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestCachedCheckerNotBefore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	payload := randomPayload(t)
+
+	store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Eq(payload.Username)).Times(1).Return(payload.IssuedAt.Add(time.Second), nil)
+	store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(0)
+
+	checker := NewCachedChecker(store, 10, time.Minute)
+	revoked, err := checker.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestCachedCheckerPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	payload := randomPayload(t)
+
+	gomock.InOrder(
+		store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows),
+		store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(false, nil),
+		store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows),
+		store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(true, nil),
+	)
+
+	checker := NewCachedChecker(store, 10, time.Minute)
+	revoked, err := checker.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	checker.Purge()
+	revoked, err = checker.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestCachedCheckerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	payload := randomPayload(t)
+
+	store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrConnDone)
+
+	checker := NewCachedChecker(store, 10, time.Minute)
+	_, err := checker.IsRevoked(context.Background(), payload)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestCachedCheckerNotRevokedTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	payload := randomPayload(t)
+
+	// 其他副本撤销的token在"未撤销"的缓存过期后即被发现
+	gomock.InOrder(
+		store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows),
+		store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(false, nil),
+		store.EXPECT().GetTokenNotBefore(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows),
+		store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(true, nil),
+	)
+
+	checker := NewCachedChecker(store, 10, time.Minute)
+	require.Equal(t, MaxNotRevokedTTL, checker.notRevokedTTL)
+	checker.notRevokedTTL = time.Millisecond
+
+	revoked, err := checker.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	time.Sleep(5 * time.Millisecond)
+	revoked, err = checker.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// 已撤销的结果按缓存ttl保留
+	revoked, err = checker.IsRevoked(context.Background(), payload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
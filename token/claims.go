@@ -0,0 +1,166 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+// ClaimRules are the issuer and audience a maker stamps on the tokens it
+// creates and requires of the tokens it verifies. Empty values are neither
+// stamped nor checked.
+type ClaimRules struct {
+	Issuer   string
+	Audience string
+}
+
+func NewClaimRules(config utils.Config) ClaimRules {
+	return ClaimRules{
+		Issuer:   config.TokenIssuer,
+		Audience: config.TokenAudience,
+	}
+}
+
+func (rules ClaimRules) stamp(payload *Payload) {
+	if rules.Issuer != "" {
+		payload.Issuer = rules.Issuer
+	}
+	if rules.Audience != "" && len(payload.Audience) == 0 {
+		payload.Audience = []string{rules.Audience}
+	}
+}
+
+func (rules ClaimRules) verify(payload *Payload) error {
+	if rules.Issuer != "" && payload.Issuer != rules.Issuer {
+		return ErrInvalidToken
+	}
+	if rules.Audience != "" && !slices.Contains(payload.Audience, rules.Audience) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// jwtClaims is the JWT form of a payload. It uses the registered claim names
+// and NumericDate times, so verifiers using any JWT library, e.g. with the
+// keys published at the JWKS endpoint, enforce exp, nbf, iss and aud.
+type jwtClaims struct {
+	ID        string           `json:"jti,omitempty"`
+	Subject   string           `json:"sub,omitempty"`
+	Issuer    string           `json:"iss,omitempty"`
+	Audience  jwt.ClaimStrings `json:"aud,omitempty"`
+	IssuedAt  *numericDate     `json:"iat,omitempty"`
+	NotBefore *numericDate     `json:"nbf,omitempty"`
+	ExpiresAt *numericDate     `json:"exp,omitempty"`
+	Roles     []string         `json:"roles,omitempty"`
+	Scopes    []string         `json:"scopes,omitempty"`
+	TokenType string           `json:"token_type,omitempty"`
+}
+
+func newJWTClaims(payload *Payload) *jwtClaims {
+	return &jwtClaims{
+		ID:        payload.Id.String(),
+		Subject:   payload.Username,
+		Issuer:    payload.Issuer,
+		Audience:  payload.Audience,
+		IssuedAt:  newNumericDate(payload.IssuedAt),
+		NotBefore: newNumericDate(payload.NotBefore),
+		ExpiresAt: newNumericDate(payload.ExpiredAt),
+		Roles:     payload.Roles,
+		Scopes:    payload.Scopes,
+		TokenType: payload.TokenType,
+	}
+}
+
+func (claims *jwtClaims) payload() (*Payload, error) {
+	id, err := uuid.Parse(claims.ID)
+	if err != nil || claims.Subject == "" || claims.IssuedAt == nil || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+	payload := &Payload{
+		Id:        id,
+		Username:  claims.Subject,
+		Roles:     claims.Roles,
+		Scopes:    claims.Scopes,
+		TokenType: claims.TokenType,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiredAt: claims.ExpiresAt.Time,
+	}
+	if claims.NotBefore != nil {
+		payload.NotBefore = claims.NotBefore.Time
+	}
+	return payload, nil
+}
+
+func (claims *jwtClaims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return claims.ExpiresAt.jwt(), nil
+}
+
+func (claims *jwtClaims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return claims.IssuedAt.jwt(), nil
+}
+
+func (claims *jwtClaims) GetNotBefore() (*jwt.NumericDate, error) {
+	return claims.NotBefore.jwt(), nil
+}
+
+func (claims *jwtClaims) GetIssuer() (string, error) {
+	return claims.Issuer, nil
+}
+
+func (claims *jwtClaims) GetSubject() (string, error) {
+	return claims.Subject, nil
+}
+
+func (claims *jwtClaims) GetAudience() (jwt.ClaimStrings, error) {
+	return claims.Audience, nil
+}
+
+// numericDate is a NumericDate kept to the microsecond, like the payload.
+// jwt.NumericDate truncates to jwt.TimePrecision, which defaults to seconds;
+// that would truncate iat, so a token issued in the same second as a
+// password change would count as issued before it.
+type numericDate struct {
+	time.Time
+}
+
+func newNumericDate(t time.Time) *numericDate {
+	if t.IsZero() {
+		return nil
+	}
+	return &numericDate{Time: t.Truncate(time.Microsecond)}
+}
+
+// jwt returns the date for the validator of golang-jwt without truncating it
+func (date *numericDate) jwt() *jwt.NumericDate {
+	if date == nil {
+		return nil
+	}
+	return &jwt.NumericDate{Time: date.Time}
+}
+
+func (date numericDate) MarshalJSON() ([]byte, error) {
+	micros := date.UnixMicro()
+	return fmt.Appendf(nil, "%d.%06d", micros/1e6, micros%1e6), nil
+}
+
+func (date *numericDate) UnmarshalJSON(b []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(b, &number); err != nil {
+		return err
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return err
+	}
+	// float64的误差远小于一微秒，舍入后和签发时的时间相等
+	date.Time = time.UnixMicro(int64(math.Round(seconds * 1e6)))
+	return nil
+}
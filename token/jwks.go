@@ -0,0 +1,80 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is the public half of a signing key as described in RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeyMaker is a Maker whose tokens can be verified with public keys only
+type PublicKeyMaker interface {
+	Maker
+	JWKS() JWKS
+}
+
+// NewJWK describes the public key. The key id is the RFC 7638 thumbprint of
+// the key, so it stays the same however the key is loaded.
+func NewJWK(publicKey crypto.PublicKey, algorithm string) (JWK, error) {
+	var jwk JWK
+	var members any
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		jwk = JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(key),
+		}
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	case *rsa.PublicKey:
+		jwk = JWK{
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	// 指纹按字典序序列化必需成员后取SHA-256
+	data, err := json.Marshal(members)
+	if err != nil {
+		return JWK{}, err
+	}
+	thumbprint := sha256.Sum256(data)
+
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	jwk.Use = "sig"
+	jwk.Algorithm = algorithm
+	return jwk, nil
+}
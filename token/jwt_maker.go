@@ -17,28 +17,41 @@ const (
 // 而不是单独创建signature后将JWT的三部分拼接在一起
 
 type JWTMaker struct {
-	secretKey string //用来创建token的密钥
+	keyring *Keyring //用来创建和验证token的密钥
+	rules   ClaimRules
 }
 
 func NewJWTMaker(secretKey string) (*JWTMaker, error) {
-	if len(secretKey) < minSecretKeySize {
-		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+	return NewJWTMakerWithKeyring(NewKeyring(NewSymmetricKey([]byte(secretKey))), ClaimRules{})
+}
+
+// NewJWTMakerWithKeyring creates a maker signing with the active key of the
+// keyring and verifying with any of its keys
+func NewJWTMakerWithKeyring(keyring *Keyring, rules ClaimRules) (*JWTMaker, error) {
+	for _, key := range keyring.Keys() {
+		if len(key.Secret) < minSecretKeySize {
+			return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+		}
 	}
 	return &JWTMaker{
-		secretKey: secretKey,
+		keyring: keyring,
+		rules:   rules,
 	}, nil
 }
 
 // token的创建，使用NewWithClaims需要指定使用的签名算法、再输入payload，这样就能创建Token
 // 使用创建的Token类型的变量的SignedString，以密钥作为参数，SignedString返回创建完整的token
 // 其中duration指的是创建和过期之间间隔的时间
-func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *JWTMaker) CreateToken(username string, duration time.Duration, options ...PayloadOption) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration, options...)
 	if err != nil {
 		return "", nil, err
 	}
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	token, err := jwtToken.SignedString([]byte(maker.secretKey))
+	maker.rules.stamp(payload)
+	key := maker.keyring.Active()
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newJWTClaims(payload))
+	jwtToken.Header["kid"] = key.ID
+	token, err := jwtToken.SignedString(key.Secret)
 	if err != nil {
 		return "", nil, err
 	}
@@ -53,23 +66,41 @@ func (jwtmaker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		if !ok {
 			return nil, fmt.Errorf("unexpected signing method")
 		}
-		return []byte(jwtmaker.secretKey), nil
+		kid, _ := token.Header["kid"].(string)
+		key, err := jwtmaker.keyring.Lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		return key.Secret, nil
 	}
 
-	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, jwtKeyFunc)
+	jwtToken, err := jwt.ParseWithClaims(token, &jwtClaims{}, jwtKeyFunc)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, jwt.ErrTokenExpired
 		}
+		if errors.Is(err, jwt.ErrTokenNotValidYet) {
+			return nil, jwt.ErrTokenNotValidYet
+		}
 		return nil, ErrInvalidToken
 	}
 
-	payload, ok := jwtToken.Claims.(*Payload)
-
+	claims, ok := jwtToken.Claims.(*jwtClaims)
 	if !ok {
 		return nil, ErrInvalidToken
 	}
+	payload, err := claims.payload()
+	if err != nil {
+		return nil, err
+	}
 
+	if err := jwtmaker.rules.verify(payload); err != nil {
+		return nil, err
+	}
 	return payload, nil
 }
+
+func (maker *JWTMaker) Keyring() *Keyring {
+	return maker.keyring
+}
@@ -62,7 +62,7 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	payload, err := NewPayload(username, duration)
 	require.NoError(t, err)
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, newJWTClaims(payload))
 	token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
@@ -74,3 +74,196 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	require.EqualError(t, err, ErrInvalidToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestJWTMakerKeyRotation(t *testing.T) {
+	oldKey := utils.RandomString(32)
+	newKey := utils.RandomString(32)
+
+	config := utils.Config{TokenAlgorithm: AlgorithmHS256, TokenSymmetricKey: oldKey}
+	maker, err := NewMaker(config)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+
+	// 新密钥生效，旧密钥仍可验证之前签发的token
+	config.TokenSymmetricKey = newKey
+	config.TokenPreviousSymmetricKeys = []string{oldKey}
+	err = ReloadKeys(maker, config)
+	require.NoError(t, err)
+
+	newToken, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, NewSymmetricKey([]byte(newKey)).ID, jwtKeyID(t, newToken))
+	require.Equal(t, NewSymmetricKey([]byte(oldKey)).ID, jwtKeyID(t, oldToken))
+
+	for _, token := range []string{oldToken, newToken} {
+		_, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+	}
+
+	// 旧密钥退役后，它签发的token不再有效
+	config.TokenPreviousSymmetricKeys = nil
+	err = ReloadKeys(maker, config)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+}
+
+func TestJWTMakerReloadKeysInvalid(t *testing.T) {
+	secretKey := utils.RandomString(32)
+	maker, err := NewMaker(utils.Config{TokenSymmetricKey: secretKey})
+	require.NoError(t, err)
+
+	// 无效的配置不会替换掉正在使用的密钥
+	err = ReloadKeys(maker, utils.Config{TokenSymmetricKey: "short"})
+	require.Error(t, err)
+	err = ReloadKeys(maker, utils.Config{TokenAlgorithm: AlgorithmPasetoV2Local, TokenSymmetricKey: utils.RandomString(32)})
+	require.Error(t, err)
+
+	token, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, NewSymmetricKey([]byte(secretKey)).ID, jwtKeyID(t, token))
+}
+
+func TestJWTTokenWithoutKeyID(t *testing.T) {
+	secretKey := utils.RandomString(32)
+	maker, err := NewJWTMaker(secretKey)
+	require.NoError(t, err)
+
+	// 引入kid之前签发的token用当前密钥验证
+	payload, err := NewPayload(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newJWTClaims(payload)).SignedString([]byte(secretKey))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	// 未知的kid直接拒绝
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newJWTClaims(payload))
+	jwtToken.Header["kid"] = "unknown"
+	token, err = jwtToken.SignedString([]byte(secretKey))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func jwtKeyID(t *testing.T, token string) string {
+	jwtToken, _, err := jwt.NewParser().ParseUnverified(token, &jwtClaims{})
+	require.NoError(t, err)
+	kid, _ := jwtToken.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTMakerClaims(t *testing.T) {
+	config := utils.Config{
+		TokenSymmetricKey: utils.RandomString(32),
+		TokenIssuer:       "simplebank",
+		TokenAudience:     "simplebank",
+	}
+	maker, err := NewMaker(config)
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute,
+		WithRoles("depositor"),
+		WithScopes("accounts:read"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "simplebank", payload.Issuer)
+	require.Equal(t, []string{"simplebank"}, payload.Audience)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.True(t, payload.HasRole("depositor"))
+	require.True(t, payload.HasScope("accounts:read"))
+	require.False(t, payload.HasScope("transfers:write"))
+	require.WithinDuration(t, payload.IssuedAt, payload.NotBefore, time.Second)
+	require.NoError(t, payload.VerifyAccess())
+
+	// 刷新token保留类型，不能当作访问token使用
+	token, _, err = maker.CreateToken(utils.RandomOwnerName(), time.Minute, WithTokenType(TokenTypeRefresh))
+	require.NoError(t, err)
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, TokenTypeRefresh, payload.TokenType)
+	require.ErrorIs(t, payload.VerifyAccess(), ErrNotAccessToken)
+}
+
+func TestJWTMakerClaimRules(t *testing.T) {
+	secretKey := utils.RandomString(32)
+	config := utils.Config{
+		TokenSymmetricKey: secretKey,
+		TokenIssuer:       "simplebank",
+		TokenAudience:     "simplebank",
+	}
+	maker, err := NewMaker(config)
+	require.NoError(t, err)
+
+	otherIssuer, err := NewMaker(utils.Config{TokenSymmetricKey: secretKey, TokenIssuer: "other", TokenAudience: "simplebank"})
+	require.NoError(t, err)
+	noClaims, err := NewJWTMaker(secretKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		maker Maker
+		opts  []PayloadOption
+		err   error
+	}{
+		{name: "OK", maker: maker, err: nil},
+		{name: "OtherAudienceIncluded", maker: maker, opts: []PayloadOption{WithAudience("ledger", "simplebank")}, err: nil},
+		{name: "WrongAudience", maker: maker, opts: []PayloadOption{WithAudience("ledger")}, err: ErrInvalidToken},
+		{name: "WrongIssuer", maker: otherIssuer, err: ErrInvalidToken},
+		{name: "MissingClaims", maker: noClaims, err: ErrInvalidToken},
+		{name: "NotYetValid", maker: maker, opts: []PayloadOption{WithNotBefore(time.Now().Add(time.Minute))}, err: jwt.ErrTokenNotValidYet},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, _, err := tc.maker.CreateToken(utils.RandomOwnerName(), time.Minute, tc.opts...)
+			require.NoError(t, err)
+
+			payload, err := maker.VerifyToken(token)
+			if tc.err != nil {
+				require.EqualError(t, err, tc.err.Error())
+				require.Nil(t, payload)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestJWTRegisteredClaims(t *testing.T) {
+	secretKey := utils.RandomString(32)
+	maker, err := NewMaker(utils.Config{TokenSymmetricKey: secretKey, TokenIssuer: "simplebank", TokenAudience: "simplebank"})
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+
+	// 下游服务只依赖标准JWT库即可校验exp、nbf、iss和aud
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(secretKey), nil
+	}, jwt.WithIssuer("simplebank"), jwt.WithAudience("simplebank"), jwt.WithExpirationRequired())
+	require.NoError(t, err)
+	require.Equal(t, payload.Id.String(), claims["jti"])
+	require.Equal(t, payload.Username, claims["sub"])
+	for _, name := range []string{"iat", "nbf", "exp"} {
+		require.IsType(t, float64(0), claims[name])
+	}
+
+	// 签发后修改密码的判断依赖iat的精度，且不能靠改golang-jwt的全局精度
+	verified, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.True(t, payload.IssuedAt.Equal(verified.IssuedAt))
+	require.True(t, payload.ExpiredAt.Equal(verified.ExpiredAt))
+	require.Equal(t, time.Second, jwt.TimePrecision)
+}
@@ -0,0 +1,139 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PublicJWTMaker signs JWTs with an EdDSA or RS256 private key, so that
+// services holding only the public key can verify them
+type PublicJWTMaker struct {
+	keyring *Keyring
+	rules   ClaimRules
+}
+
+// NewPublicJWTMaker picks EdDSA for Ed25519 keys and RS256 for RSA keys.
+// privateKey may be nil for a maker that only verifies tokens.
+func NewPublicJWTMaker(privateKey crypto.Signer, publicKey crypto.PublicKey) (*PublicJWTMaker, error) {
+	key, err := NewAsymmetricKey(privateKey, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewPublicJWTMakerWithKeyring(NewKeyring(key), ClaimRules{})
+}
+
+// NewPublicJWTMakerWithKeyring creates a maker signing with the active key
+// of the keyring and verifying with any of its keys
+func NewPublicJWTMakerWithKeyring(keyring *Keyring, rules ClaimRules) (*PublicJWTMaker, error) {
+	for _, key := range keyring.Keys() {
+		if _, err := jwtSigningMethod(key.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+	return &PublicJWTMaker{
+		keyring: keyring,
+		rules:   rules,
+	}, nil
+}
+
+// jwtSigningMethod returns the only algorithm accepted for the key
+func jwtSigningMethod(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeySize {
+			return nil, fmt.Errorf("invalid key size: RSA keys must be at least %d bits", minRSAKeySize)
+		}
+		return jwt.SigningMethodRS256, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+func (maker *PublicJWTMaker) CreateToken(username string, duration time.Duration, options ...PayloadOption) (string, *Payload, error) {
+	key := maker.keyring.Active()
+	if key.PrivateKey == nil {
+		return "", nil, ErrSigningKeyMissing
+	}
+	method, err := jwtSigningMethod(key.PublicKey)
+	if err != nil {
+		return "", nil, err
+	}
+	payload, err := NewPayload(username, duration, options...)
+	if err != nil {
+		return "", nil, err
+	}
+	maker.rules.stamp(payload)
+	jwtToken := jwt.NewWithClaims(method, newJWTClaims(payload))
+	jwtToken.Header["kid"] = key.ID
+	token, err := jwtToken.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+func (maker *PublicJWTMaker) VerifyToken(token string) (*Payload, error) {
+	//只接受与密钥匹配的算法，防止把公钥当作HMAC密钥的算法混淆攻击
+	jwtKeyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := maker.keyring.Lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		method, err := jwtSigningMethod(key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return key.PublicKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &jwtClaims{}, jwtKeyFunc)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, jwt.ErrTokenExpired
+		}
+		if errors.Is(err, jwt.ErrTokenNotValidYet) {
+			return nil, jwt.ErrTokenNotValidYet
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := jwtToken.Claims.(*jwtClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	payload, err := claims.payload()
+	if err != nil {
+		return nil, err
+	}
+	if err := maker.rules.verify(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (maker *PublicJWTMaker) JWKS() JWKS {
+	keys := maker.keyring.Keys()
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		method, _ := jwtSigningMethod(key.PublicKey)
+		jwk, _ := NewJWK(key.PublicKey, method.Alg())
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func (maker *PublicJWTMaker) Keyring() *Keyring {
+	return maker.keyring
+}
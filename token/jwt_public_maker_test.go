@@ -0,0 +1,121 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func randomSigningKeys(t *testing.T) map[string]crypto.Signer {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return map[string]crypto.Signer{
+		AlgorithmEdDSA: edKey,
+		AlgorithmRS256: rsaKey,
+	}
+}
+
+func TestPublicJWTMaker(t *testing.T) {
+	for alg, privateKey := range randomSigningKeys(t) {
+		t.Run(alg, func(t *testing.T) {
+			maker, err := NewPublicJWTMaker(privateKey, privateKey.Public())
+			require.NoError(t, err)
+
+			username := utils.RandomOwnerName()
+			duration := time.Minute
+			issuedAt := time.Now()
+			expiredAt := issuedAt.Add(duration)
+
+			token, payload, err := maker.CreateToken(username, duration)
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+			require.NotEmpty(t, payload)
+
+			// 只持有公钥的一方同样可以验证token
+			verifier, err := NewPublicJWTMaker(nil, privateKey.Public())
+			require.NoError(t, err)
+			payload, err = verifier.VerifyToken(token)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload.Id)
+			require.Equal(t, username, payload.Username)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+
+			_, _, err = verifier.CreateToken(username, duration)
+			require.ErrorIs(t, err, ErrSigningKeyMissing)
+
+			jwtToken, _, err := jwt.NewParser().ParseUnverified(token, &jwtClaims{})
+			require.NoError(t, err)
+			require.Equal(t, alg, jwtToken.Header["alg"])
+			require.Equal(t, maker.JWKS().Keys[0].KeyID, jwtToken.Header["kid"])
+		})
+	}
+}
+
+func TestExpiredPublicJWTToken(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	maker, err := NewPublicJWTMaker(privateKey, privateKey.Public())
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(utils.RandomOwnerName(), -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.EqualError(t, err, jwt.ErrTokenExpired.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidPublicJWTTokenWrongKey(t *testing.T) {
+	keys := randomSigningKeys(t)
+	otherKeys := randomSigningKeys(t)
+
+	maker, err := NewPublicJWTMaker(keys[AlgorithmEdDSA], keys[AlgorithmEdDSA].Public())
+	require.NoError(t, err)
+	token, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+
+	for _, key := range otherKeys {
+		verifier, err := NewPublicJWTMaker(nil, key.Public())
+		require.NoError(t, err)
+		payload, err := verifier.VerifyToken(token)
+		require.EqualError(t, err, ErrInvalidToken.Error())
+		require.Nil(t, payload)
+	}
+}
+
+func TestInvalidPublicJWTTokenAlgHS256(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+
+	// 用公钥作为HMAC密钥伪造token
+	payload, err := NewPayload(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newJWTClaims(payload)).SignedString([]byte(publicKey))
+	require.NoError(t, err)
+
+	maker, err := NewPublicJWTMaker(nil, publicKey)
+	require.NoError(t, err)
+	payload, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPublicJWTMakerWeakRSAKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = NewPublicJWTMaker(privateKey, privateKey.Public())
+	require.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package token
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+var ErrUnknownKeyID = errors.New("token is signed with an unknown or retired key")
+
+// Key is one key of a Keyring. Symmetric algorithms use Secret, asymmetric
+// ones use PrivateKey and PublicKey.
+type Key struct {
+	ID         string
+	Secret     []byte
+	PrivateKey crypto.Signer //为nil时只能验证token
+	PublicKey  crypto.PublicKey
+}
+
+// NewSymmetricKey derives the key id from the secret with HMAC, so that all
+// instances agree on it without the id revealing anything about the secret
+func NewSymmetricKey(secret []byte) Key {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("token-key-id"))
+	return Key{
+		ID:     base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12]),
+		Secret: secret,
+	}
+}
+
+// NewAsymmetricKey uses the JWK thumbprint of the public key as the key id.
+// privateKey may be nil for a key that only verifies tokens.
+func NewAsymmetricKey(privateKey crypto.Signer, publicKey crypto.PublicKey) (Key, error) {
+	jwk, err := NewJWK(publicKey, "")
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{
+		ID:         jwk.KeyID,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}, nil
+}
+
+// Keyring holds the active key that signs new tokens and every key tokens
+// are still verified with. Dropping a key from the ring retires it, so the
+// tokens it signed stop verifying. It is safe for concurrent use.
+type Keyring struct {
+	mu     sync.RWMutex
+	active Key
+	keys   map[string]Key
+}
+
+// NewKeyring creates a keyring signing with active and also accepting the
+// tokens signed by previous
+func NewKeyring(active Key, previous ...Key) *Keyring {
+	keyring := &Keyring{
+		active: active,
+		keys:   map[string]Key{active.ID: active},
+	}
+	for _, key := range previous {
+		if _, ok := keyring.keys[key.ID]; !ok {
+			keyring.keys[key.ID] = key
+		}
+	}
+	return keyring
+}
+
+// Active returns the key new tokens are signed with
+func (keyring *Keyring) Active() Key {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+	return keyring.active
+}
+
+// Lookup returns the key with the given id. Tokens issued before key ids
+// were introduced carry none and are verified with the active key.
+func (keyring *Keyring) Lookup(id string) (Key, error) {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+	if id == "" {
+		return keyring.active, nil
+	}
+	key, ok := keyring.keys[id]
+	if !ok {
+		return Key{}, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+// Keys returns every key of the ring, the active key first
+func (keyring *Keyring) Keys() []Key {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+	keys := make([]Key, 0, len(keyring.keys))
+	for id, key := range keyring.keys {
+		if id != keyring.active.ID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return append([]Key{keyring.active}, keys...)
+}
+
+// replace swaps in the keys of other, which the maker has already validated
+func (keyring *Keyring) replace(other *Keyring) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+	keyring.active = other.active
+	keyring.keys = other.keys
+}
+
+// LoadKeyring loads the keys of config.TokenAlgorithm: the active key plus
+// the previous keys that still verify tokens signed before a rotation
+func LoadKeyring(config utils.Config) (*Keyring, error) {
+	switch config.TokenAlgorithm {
+	case "", AlgorithmHS256, AlgorithmPasetoV2Local:
+		previous := make([]Key, 0, len(config.TokenPreviousSymmetricKeys))
+		for _, secret := range config.TokenPreviousSymmetricKeys {
+			previous = append(previous, NewSymmetricKey([]byte(secret)))
+		}
+		return NewKeyring(NewSymmetricKey([]byte(config.TokenSymmetricKey)), previous...), nil
+	}
+
+	privateKey, publicKey, err := LoadKeyPair(config.TokenPrivateKeyFile, config.TokenPublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	active, err := NewAsymmetricKey(privateKey, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	previous := make([]Key, 0, len(config.TokenPreviousPublicKeyFiles))
+	for _, file := range config.TokenPreviousPublicKeyFiles {
+		_, publicKey, err := LoadKeyPair("", file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load previous key %s: %w", file, err)
+		}
+		key, err := NewAsymmetricKey(nil, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	return NewKeyring(active, previous...), nil
+}
@@ -0,0 +1,120 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const minRSAKeySize = 2048
+
+var ErrSigningKeyMissing = errors.New("maker has no private key to sign tokens")
+
+// ParsePrivateKeyPEM parses an Ed25519 or RSA private key in PKCS#8 form,
+// or an RSA private key in PKCS#1 form
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// ParsePublicKeyPEM parses an Ed25519 or RSA public key in PKIX form, or an
+// RSA public key in PKCS#1 form
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return key, nil
+	case *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// LoadKeyPair reads the key pair from PEM files. Either path may be empty:
+// without a private key the pair can only verify tokens, and without a
+// public key it is derived from the private key.
+func LoadKeyPair(privateKeyFile, publicKeyFile string) (crypto.Signer, crypto.PublicKey, error) {
+	var privateKey crypto.Signer
+	var publicKey crypto.PublicKey
+
+	if privateKeyFile != "" {
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		privateKey, err = ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		publicKey = privateKey.Public()
+	}
+
+	if publicKeyFile != "" {
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read public key file: %w", err)
+		}
+		key, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if privateKey != nil && !key.(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey) {
+			return nil, nil, errors.New("public key does not match private key")
+		}
+		publicKey = key
+	}
+
+	if publicKey == nil {
+		return nil, nil, errors.New("a private or public key file is required")
+	}
+	return privateKey, publicKey, nil
+}
@@ -0,0 +1,153 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zjr71163356/simplebank/utils"
+)
+
+func writeKeyFiles(t *testing.T, privateKey crypto.Signer) (string, string) {
+	dir := t.TempDir()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	privateKeyFile := filepath.Join(dir, "private.pem")
+	err = os.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600)
+	require.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	require.NoError(t, err)
+	publicKeyFile := filepath.Join(dir, "public.pem")
+	err = os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644)
+	require.NoError(t, err)
+
+	return privateKeyFile, publicKeyFile
+}
+
+func TestLoadKeyPair(t *testing.T) {
+	for _, privateKey := range randomSigningKeys(t) {
+		privateKeyFile, publicKeyFile := writeKeyFiles(t, privateKey)
+
+		loadedPrivateKey, publicKey, err := LoadKeyPair(privateKeyFile, publicKeyFile)
+		require.NoError(t, err)
+		require.True(t, privateKey.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey))
+		require.NotNil(t, loadedPrivateKey)
+
+		loadedPrivateKey, publicKey, err = LoadKeyPair("", publicKeyFile)
+		require.NoError(t, err)
+		require.Nil(t, loadedPrivateKey)
+		require.NotNil(t, publicKey)
+	}
+
+	// 公钥与私钥不匹配
+	keys := randomSigningKeys(t)
+	privateKeyFile, _ := writeKeyFiles(t, keys[AlgorithmEdDSA])
+	_, otherPublicKeyFile := writeKeyFiles(t, randomSigningKeys(t)[AlgorithmEdDSA])
+	_, _, err := LoadKeyPair(privateKeyFile, otherPublicKeyFile)
+	require.Error(t, err)
+
+	_, _, err = LoadKeyPair("", "")
+	require.Error(t, err)
+}
+
+func TestNewMaker(t *testing.T) {
+	keys := randomSigningKeys(t)
+	edPrivateKeyFile, edPublicKeyFile := writeKeyFiles(t, keys[AlgorithmEdDSA])
+	rsaPrivateKeyFile, _ := writeKeyFiles(t, keys[AlgorithmRS256])
+
+	testCases := []struct {
+		name      string
+		config    utils.Config
+		publicKey bool
+		ok        bool
+	}{
+		{name: "Default", config: utils.Config{TokenSymmetricKey: utils.RandomString(32)}, ok: true},
+		{name: "PasetoV2Local", config: utils.Config{TokenAlgorithm: AlgorithmPasetoV2Local, TokenSymmetricKey: utils.RandomString(32)}, ok: true},
+		{name: "EdDSA", config: utils.Config{TokenAlgorithm: AlgorithmEdDSA, TokenPrivateKeyFile: edPrivateKeyFile}, publicKey: true, ok: true},
+		{name: "RS256", config: utils.Config{TokenAlgorithm: AlgorithmRS256, TokenPrivateKeyFile: rsaPrivateKeyFile}, publicKey: true, ok: true},
+		{name: "PasetoV4Public", config: utils.Config{TokenAlgorithm: AlgorithmPasetoV4, TokenPrivateKeyFile: edPrivateKeyFile, TokenPublicKeyFile: edPublicKeyFile}, publicKey: true, ok: true},
+		{name: "KeyTypeMismatch", config: utils.Config{TokenAlgorithm: AlgorithmRS256, TokenPrivateKeyFile: edPrivateKeyFile}},
+		{name: "MissingKeyFile", config: utils.Config{TokenAlgorithm: AlgorithmEdDSA}},
+		{name: "UnknownAlgorithm", config: utils.Config{TokenAlgorithm: "ES256", TokenPrivateKeyFile: edPrivateKeyFile}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewMaker(tc.config)
+			if !tc.ok {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			token, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+			require.NoError(t, err)
+			_, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+
+			publicKeyMaker, ok := maker.(PublicKeyMaker)
+			require.Equal(t, tc.publicKey, ok)
+			if ok {
+				require.Len(t, publicKeyMaker.JWKS().Keys, 1)
+			}
+		})
+	}
+}
+
+// 使用RFC 8037附录A.3中的Ed25519公钥及其指纹
+func TestJWKThumbprint(t *testing.T) {
+	publicKey := ed25519.PublicKey{
+		0xd7, 0x5a, 0x98, 0x01, 0x82, 0xb1, 0x0a, 0xb7, 0xd5, 0x4b, 0xfe, 0xd3, 0xc9, 0x64, 0x07, 0x3a,
+		0x0e, 0xe1, 0x72, 0xf3, 0xda, 0xa6, 0x23, 0x25, 0xaf, 0x02, 0x1a, 0x68, 0xf7, 0x07, 0x51, 0x1a,
+	}
+	jwk, err := NewJWK(publicKey, AlgorithmEdDSA)
+	require.NoError(t, err)
+	require.Equal(t, "OKP", jwk.KeyType)
+	require.Equal(t, "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo", jwk.X)
+	require.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", jwk.KeyID)
+}
+
+func TestPublicKeyRotation(t *testing.T) {
+	oldPrivateKeyFile, oldPublicKeyFile := writeKeyFiles(t, randomSigningKeys(t)[AlgorithmEdDSA])
+	newPrivateKeyFile, _ := writeKeyFiles(t, randomSigningKeys(t)[AlgorithmEdDSA])
+
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmPasetoV4} {
+		t.Run(algorithm, func(t *testing.T) {
+			config := utils.Config{TokenAlgorithm: algorithm, TokenPrivateKeyFile: oldPrivateKeyFile}
+			maker, err := NewMaker(config)
+			require.NoError(t, err)
+
+			oldToken, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+			require.NoError(t, err)
+
+			config.TokenPrivateKeyFile = newPrivateKeyFile
+			config.TokenPreviousPublicKeyFiles = []string{oldPublicKeyFile}
+			err = ReloadKeys(maker, config)
+			require.NoError(t, err)
+
+			// JWKS同时发布新旧公钥，新公钥在前
+			jwks := maker.(PublicKeyMaker).JWKS()
+			require.Len(t, jwks.Keys, 2)
+			require.Equal(t, maker.Keyring().Active().ID, jwks.Keys[0].KeyID)
+
+			_, err = maker.VerifyToken(oldToken)
+			require.NoError(t, err)
+
+			config.TokenPreviousPublicKeyFiles = nil
+			err = ReloadKeys(maker, config)
+			require.NoError(t, err)
+			require.Len(t, maker.(PublicKeyMaker).JWKS().Keys, 1)
+
+			_, err = maker.VerifyToken(oldToken)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+		})
+	}
+}
@@ -1,8 +1,84 @@
 package token
 
-import "time"
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	"github.com/zjr71163356/simplebank/utils"
+)
 
 type Maker interface {
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	CreateToken(username string, duration time.Duration, options ...PayloadOption) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
+
+// KeyringMaker is a Maker whose keys can be rotated without a restart
+type KeyringMaker interface {
+	Maker
+	Keyring() *Keyring
+}
+
+// 支持的TOKEN_ALGORITHM取值，为空时沿用HS256
+const (
+	AlgorithmHS256         = "HS256"
+	AlgorithmPasetoV2Local = "v2.local"
+	AlgorithmEdDSA         = "EdDSA"
+	AlgorithmRS256         = "RS256"
+	AlgorithmPasetoV4      = "v4.public"
+)
+
+// NewMaker creates the maker selected by config.TokenAlgorithm with the
+// keyring and claim rules loaded from the config
+func NewMaker(config utils.Config) (KeyringMaker, error) {
+	keyring, err := LoadKeyring(config)
+	if err != nil {
+		return nil, err
+	}
+	rules := NewClaimRules(config)
+
+	switch config.TokenAlgorithm {
+	case "", AlgorithmHS256:
+		return NewJWTMakerWithKeyring(keyring, rules)
+	case AlgorithmPasetoV2Local:
+		return NewPasetoMakerWithKeyring(keyring, rules)
+	case AlgorithmEdDSA:
+		for _, key := range keyring.Keys() {
+			if _, ok := key.PublicKey.(ed25519.PublicKey); !ok {
+				return nil, fmt.Errorf("%s requires Ed25519 keys", config.TokenAlgorithm)
+			}
+		}
+		return NewPublicJWTMakerWithKeyring(keyring, rules)
+	case AlgorithmRS256:
+		for _, key := range keyring.Keys() {
+			if _, ok := key.PublicKey.(*rsa.PublicKey); !ok {
+				return nil, fmt.Errorf("%s requires RSA keys", config.TokenAlgorithm)
+			}
+		}
+		return NewPublicJWTMakerWithKeyring(keyring, rules)
+	case AlgorithmPasetoV4:
+		return NewPasetoPublicMakerWithKeyring(keyring, rules)
+	default:
+		return nil, fmt.Errorf("unsupported token algorithm %q", config.TokenAlgorithm)
+	}
+}
+
+// ReloadKeys loads the keyring from the config and swaps it into the maker,
+// so tokens are signed with the new active key from then on. The algorithm
+// and claim rules cannot change without a restart.
+func ReloadKeys(maker Maker, config utils.Config) error {
+	current, ok := maker.(KeyringMaker)
+	if !ok {
+		return fmt.Errorf("maker %T does not support key rotation", maker)
+	}
+	reloaded, err := NewMaker(config)
+	if err != nil {
+		return err
+	}
+	if fmt.Sprintf("%T", reloaded) != fmt.Sprintf("%T", current) {
+		return fmt.Errorf("token algorithm cannot change without a restart")
+	}
+	current.Keyring().replace(reloaded.Keyring())
+	return nil
+}
@@ -9,27 +9,39 @@ import (
 )
 
 type PasetoMaker struct {
-	paseto       *paseto.V2
-	symmetricKey []byte
+	paseto  *paseto.V2
+	keyring *Keyring
+	rules   ClaimRules
 }
 
 func NewPasetoMaker(symmetricKey string) (Maker, error) {
-	if len(symmetricKey) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("invaild key size:must be exactly %d characters", chacha20poly1305.KeySize)
+	return NewPasetoMakerWithKeyring(NewKeyring(NewSymmetricKey([]byte(symmetricKey))), ClaimRules{})
+}
+
+// NewPasetoMakerWithKeyring creates a maker encrypting with the active key
+// of the keyring and decrypting with any of its keys
+func NewPasetoMakerWithKeyring(keyring *Keyring, rules ClaimRules) (*PasetoMaker, error) {
+	for _, key := range keyring.Keys() {
+		if len(key.Secret) != chacha20poly1305.KeySize {
+			return nil, fmt.Errorf("invaild key size:must be exactly %d characters", chacha20poly1305.KeySize)
+		}
 	}
 	maker := &PasetoMaker{
-		paseto:       paseto.NewV2(),
-		symmetricKey: []byte(symmetricKey),
+		paseto:  paseto.NewV2(),
+		keyring: keyring,
+		rules:   rules,
 	}
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(username string, duration time.Duration, options ...PayloadOption) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration, options...)
 	if err != nil {
 		return "", nil, err
 	}
-	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	maker.rules.stamp(payload)
+	key := maker.keyring.Active()
+	token, err := maker.paseto.Encrypt(key.Secret, payload, pasetoFooter{KeyID: key.ID})
 	if err != nil {
 		return "", nil, err
 	}
@@ -37,8 +49,18 @@ func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (
 }
 
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+	//footer未加密，先从中取出kid再选择解密用的密钥
+	footer := pasetoFooter{}
+	if err := paseto.ParseFooter(token, &footer); err != nil {
+		return nil, ErrInvalidToken
+	}
+	key, err := maker.keyring.Lookup(footer.KeyID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
 	payload := &Payload{}
-	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
+	err = maker.paseto.Decrypt(token, key.Secret, payload, nil)
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
@@ -47,6 +69,14 @@ func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = maker.rules.verify(payload)
+	if err != nil {
+		return nil, err
+	}
 	return payload, nil
-	
+
+}
+
+func (maker *PasetoMaker) Keyring() *Keyring {
+	return maker.keyring
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/o1egl/paseto"
 	"github.com/stretchr/testify/require"
 	"github.com/zjr71163356/simplebank/utils"
 )
@@ -54,3 +55,115 @@ func TestExpiredPasetoToken(t *testing.T) {
 	require.Nil(t, payload)
 
 }
+
+func TestPasetoMakerKeyRotation(t *testing.T) {
+	oldKey := utils.RandomString(32)
+	newKey := utils.RandomString(32)
+
+	config := utils.Config{TokenAlgorithm: AlgorithmPasetoV2Local, TokenSymmetricKey: oldKey}
+	maker, err := NewMaker(config)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+
+	// 新密钥生效，旧密钥仍可解密之前签发的token
+	config.TokenSymmetricKey = newKey
+	config.TokenPreviousSymmetricKeys = []string{oldKey}
+	err = ReloadKeys(maker, config)
+	require.NoError(t, err)
+
+	newToken, _, err := maker.CreateToken(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, NewSymmetricKey([]byte(newKey)).ID, pasetoKeyID(t, newToken))
+	require.Equal(t, NewSymmetricKey([]byte(oldKey)).ID, pasetoKeyID(t, oldToken))
+
+	for _, token := range []string{oldToken, newToken} {
+		_, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+	}
+
+	// 旧密钥退役后，它签发的token不再有效
+	config.TokenPreviousSymmetricKeys = nil
+	err = ReloadKeys(maker, config)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+}
+
+func TestPasetoTokenWithoutKeyID(t *testing.T) {
+	secretKey := utils.RandomString(32)
+	maker, err := NewPasetoMaker(secretKey)
+	require.NoError(t, err)
+
+	// 引入kid之前签发的token没有footer，用当前密钥解密
+	payload, err := NewPayload(utils.RandomOwnerName(), time.Minute)
+	require.NoError(t, err)
+	token, err := paseto.NewV2().Encrypt([]byte(secretKey), payload, nil)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	token, err = paseto.NewV2().Encrypt([]byte(secretKey), payload, pasetoFooter{KeyID: "unknown"})
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func pasetoKeyID(t *testing.T, token string) string {
+	footer := pasetoFooter{}
+	err := paseto.ParseFooter(token, &footer)
+	require.NoError(t, err)
+	return footer.KeyID
+}
+
+func TestPasetoMakerClaimRules(t *testing.T) {
+	secretKey := utils.RandomString(32)
+	config := utils.Config{
+		TokenAlgorithm:    AlgorithmPasetoV2Local,
+		TokenSymmetricKey: secretKey,
+		TokenIssuer:       "simplebank",
+		TokenAudience:     "simplebank",
+	}
+	maker, err := NewMaker(config)
+	require.NoError(t, err)
+
+	noClaims, err := NewPasetoMaker(secretKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		maker Maker
+		opts  []PayloadOption
+		err   error
+	}{
+		{name: "OK", maker: maker, opts: []PayloadOption{WithRoles("depositor"), WithScopes("accounts:read")}, err: nil},
+		{name: "WrongAudience", maker: maker, opts: []PayloadOption{WithAudience("ledger")}, err: ErrInvalidToken},
+		{name: "MissingClaims", maker: noClaims, err: ErrInvalidToken},
+		{name: "NotYetValid", maker: maker, opts: []PayloadOption{WithNotBefore(time.Now().Add(time.Minute))}, err: jwt.ErrTokenNotValidYet},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, created, err := tc.maker.CreateToken(utils.RandomOwnerName(), time.Minute, tc.opts...)
+			require.NoError(t, err)
+
+			payload, err := maker.VerifyToken(token)
+			if tc.err != nil {
+				require.EqualError(t, err, tc.err.Error())
+				require.Nil(t, payload)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, created.Roles, payload.Roles)
+			require.Equal(t, created.Scopes, payload.Scopes)
+			require.Equal(t, "simplebank", payload.Issuer)
+		})
+	}
+}
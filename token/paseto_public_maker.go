@@ -0,0 +1,167 @@
+package token
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const pasetoV4PublicHeader = "v4.public."
+
+// PasetoPublicMaker signs PASETO v4.public tokens with an Ed25519 key. The
+// key id is carried in the footer so verifiers can pick the right key.
+type PasetoPublicMaker struct {
+	keyring *Keyring
+	rules   ClaimRules
+}
+
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// NewPasetoPublicMaker creates a maker for Ed25519 keys. privateKey may be
+// nil for a maker that only verifies tokens.
+func NewPasetoPublicMaker(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey) (*PasetoPublicMaker, error) {
+	var signer crypto.Signer
+	if privateKey != nil {
+		signer = privateKey
+	}
+	key, err := NewAsymmetricKey(signer, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoPublicMakerWithKeyring(NewKeyring(key), ClaimRules{})
+}
+
+// NewPasetoPublicMakerWithKeyring creates a maker signing with the active
+// key of the keyring and verifying with any of its keys
+func NewPasetoPublicMakerWithKeyring(keyring *Keyring, rules ClaimRules) (*PasetoPublicMaker, error) {
+	for _, key := range keyring.Keys() {
+		publicKey, ok := key.PublicKey.(ed25519.PublicKey)
+		if !ok || len(publicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid key: must be an Ed25519 public key")
+		}
+		if key.PrivateKey != nil {
+			if _, ok := key.PrivateKey.(ed25519.PrivateKey); !ok {
+				return nil, fmt.Errorf("invalid key: must be an Ed25519 private key")
+			}
+		}
+	}
+	return &PasetoPublicMaker{
+		keyring: keyring,
+		rules:   rules,
+	}, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(username string, duration time.Duration, options ...PayloadOption) (string, *Payload, error) {
+	key := maker.keyring.Active()
+	if key.PrivateKey == nil {
+		return "", nil, ErrSigningKeyMissing
+	}
+	payload, err := NewPayload(username, duration, options...)
+	if err != nil {
+		return "", nil, err
+	}
+	maker.rules.stamp(payload)
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, err
+	}
+	footer, err := json.Marshal(pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", nil, err
+	}
+
+	signature := ed25519.Sign(key.PrivateKey.(ed25519.PrivateKey), preAuthEncode([]byte(pasetoV4PublicHeader), message, footer, nil))
+	token := pasetoV4PublicHeader +
+		base64.RawURLEncoding.EncodeToString(append(message, signature...)) +
+		"." + base64.RawURLEncoding.EncodeToString(footer)
+	return token, payload, nil
+}
+
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	if !strings.HasPrefix(token, pasetoV4PublicHeader) {
+		return nil, ErrInvalidToken
+	}
+	parts := strings.Split(token[len(pasetoV4PublicHeader):], ".")
+	if len(parts) > 2 {
+		return nil, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, ErrInvalidToken
+	}
+	var footer []byte
+	keyFooter := pasetoFooter{}
+	if len(parts) == 2 {
+		footer, err = base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+		if err := json.Unmarshal(footer, &keyFooter); err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+	key, err := maker.keyring.Lookup(keyFooter.KeyID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(key.PublicKey.(ed25519.PublicKey), preAuthEncode([]byte(pasetoV4PublicHeader), message, footer, nil), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+	if err := maker.rules.verify(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// JWKS publishes the Ed25519 keys. PASETO keys have no JWA algorithm name,
+// so alg is left out.
+func (maker *PasetoPublicMaker) JWKS() JWKS {
+	keys := maker.keyring.Keys()
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk, _ := NewJWK(key.PublicKey, "")
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func (maker *PasetoPublicMaker) Keyring() *Keyring {
+	return maker.keyring
+}
+
+// preAuthEncode is the PAE function of the PASETO spec: every piece is
+// prefixed with its length so that no two inputs encode the same way
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	le64 := func(n int) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(n)&^(1<<63))
+		buf.Write(b[:])
+	}
+	le64(len(pieces))
+	for _, piece := range pieces {
+		le64(len(piece))
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
@@ -2,6 +2,7 @@ package token
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,27 +10,89 @@ import (
 )
 
 var (
-	ErrInvalidToken = errors.New("token is invalid")
+	ErrInvalidToken   = errors.New("token is invalid")
+	ErrNotAccessToken = errors.New("token is not an access token")
 )
 
+const (
+	// TokenTypeAccess is the default type; only access tokens authenticate calls
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh tokens can only renew an access token
+	TokenTypeRefresh = "refresh"
+)
+
+// Payload holds the claims of a token. PASETO tokens carry it as is, under
+// the registered claim names; JWTs carry it as jwtClaims.
 type Payload struct {
-	Id        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	IssuedAt  time.Time `json:"issued_at"`
-	ExpiredAt time.Time `json:"expired_at"`
+	Id        uuid.UUID `json:"jti"`
+	Username  string    `json:"sub"`
+	Roles     []string  `json:"roles,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	TokenType string    `json:"token_type,omitempty"`
+	Issuer    string    `json:"iss,omitempty"`
+	Audience  []string  `json:"aud,omitempty"`
+	IssuedAt  time.Time `json:"iat"`
+	NotBefore time.Time `json:"nbf"`
+	ExpiredAt time.Time `json:"exp"`
+}
+
+// PayloadOption sets optional claims of a new payload
+type PayloadOption func(payload *Payload)
+
+func WithRoles(roles ...string) PayloadOption {
+	return func(payload *Payload) {
+		payload.Roles = roles
+	}
+}
+
+// WithScopes limits what the token may be used for, e.g. read-only access
+func WithScopes(scopes ...string) PayloadOption {
+	return func(payload *Payload) {
+		payload.Scopes = scopes
+	}
+}
+
+// WithTokenType sets what the token is for, TokenTypeAccess by default
+func WithTokenType(tokenType string) PayloadOption {
+	return func(payload *Payload) {
+		payload.TokenType = tokenType
+	}
+}
+
+// WithAudience restricts the token to the given services. Without it the
+// token is issued for the audience of the maker.
+func WithAudience(audience ...string) PayloadOption {
+	return func(payload *Payload) {
+		payload.Audience = audience
+	}
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// WithNotBefore delays the time from which the token is valid
+func WithNotBefore(notBefore time.Time) PayloadOption {
+	return func(payload *Payload) {
+		payload.NotBefore = notBefore
+	}
+}
+
+func NewPayload(username string, duration time.Duration, options ...PayloadOption) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
-	return &Payload{
+	// 与JWT的NumericDate及Postgres的时间精度保持一致
+	now := time.Now().Truncate(time.Microsecond)
+	payload := &Payload{
 		Id:        tokenID,
 		Username:  username,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(duration),
-	}, nil
+		TokenType: TokenTypeAccess,
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiredAt: now.Add(duration),
+	}
+	for _, option := range options {
+		option(payload)
+	}
+	return payload, nil
 
 }
 
@@ -38,40 +101,26 @@ func (payload *Payload) Valid() error {
 	if time.Now().After(payload.ExpiredAt) {
 		return jwt.ErrTokenExpired
 	}
+	if time.Now().Before(payload.NotBefore) {
+		return jwt.ErrTokenNotValidYet
+	}
 
 	return nil
 }
 
-// GetExpirationTime returns the expiration time claim.
-func (payload *Payload) GetExpirationTime() (*jwt.NumericDate, error) {
-	return jwt.NewNumericDate(payload.ExpiredAt), nil
-}
-
-// GetIssuedAt returns the issued at time claim.
-func (payload *Payload) GetIssuedAt() (*jwt.NumericDate, error) {
-	return jwt.NewNumericDate(payload.IssuedAt), nil
-}
-
-// GetNotBefore returns the not before time claim.
-// Payload does not implement this claim, so it returns nil.
-func (payload *Payload) GetNotBefore() (*jwt.NumericDate, error) {
-	return nil, nil // Or return an error if this claim is mandatory
-}
-
-// GetIssuer returns the issuer claim.
-// Payload does not implement this claim, so it returns an empty string.
-func (payload *Payload) GetIssuer() (string, error) {
-	return "", nil // Or return an error if this claim is mandatory
+// VerifyAccess rejects refresh tokens, and tokens issued before tokens had a
+// type, as bearer tokens of calls
+func (payload *Payload) VerifyAccess() error {
+	if payload.TokenType != TokenTypeAccess {
+		return ErrNotAccessToken
+	}
+	return nil
 }
 
-// GetSubject returns the subject claim.
-// Payload does not implement this claim, so it returns an empty string.
-func (payload *Payload) GetSubject() (string, error) {
-	return "", nil // Or return an error if this claim is mandatory
+func (payload *Payload) HasRole(role string) bool {
+	return slices.Contains(payload.Roles, role)
 }
 
-// GetAudience returns the audience claim.
-// Payload does not implement this claim, so it returns nil.
-func (payload *Payload) GetAudience() (jwt.ClaimStrings, error) {
-	return nil, nil // Or return an error if this claim is mandatory
+func (payload *Payload) HasScope(scope string) bool {
+	return slices.Contains(payload.Scopes, scope)
 }
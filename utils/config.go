@@ -1,21 +1,39 @@
 package utils
 
 import (
+	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	MigrateURL           string        `mapstructure:"MIGRATION_URL"`
-	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	Environment          string        `mapstructure:"ENVIRONMENT"`
+	DBDriver                    string        `mapstructure:"DB_DRIVER"`
+	DBSource                    string        `mapstructure:"DB_SOURCE"`
+	MigrateURL                  string        `mapstructure:"MIGRATION_URL"`
+	HTTPServerAddress           string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GRPCServerAddress           string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenSymmetricKey           string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenAlgorithm              string        `mapstructure:"TOKEN_ALGORITHM"`
+	TokenPrivateKeyFile         string        `mapstructure:"TOKEN_PRIVATE_KEY_FILE"`
+	TokenPublicKeyFile          string        `mapstructure:"TOKEN_PUBLIC_KEY_FILE"`
+	TokenPreviousSymmetricKeys  []string      `mapstructure:"TOKEN_PREVIOUS_SYMMETRIC_KEYS"`
+	TokenPreviousPublicKeyFiles []string      `mapstructure:"TOKEN_PREVIOUS_PUBLIC_KEY_FILES"`
+	TokenIssuer                 string        `mapstructure:"TOKEN_ISSUER"`
+	TokenAudience               string        `mapstructure:"TOKEN_AUDIENCE"`
+	PageTokenKey                string        `mapstructure:"PAGE_TOKEN_KEY"`
+	LedgerHashKey               string        `mapstructure:"LEDGER_HASH_KEY"`
+	AccessTokenDuration         time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration        time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RevocationCacheSize         int           `mapstructure:"REVOCATION_CACHE_SIZE"`
+	RevocationCacheTTL          time.Duration `mapstructure:"REVOCATION_CACHE_TTL"`
+	ReconciliationInterval      time.Duration `mapstructure:"RECONCILIATION_INTERVAL"`
+	QuoteDuration               time.Duration `mapstructure:"QUOTE_DURATION"`
+	FXRateSource                string        `mapstructure:"FX_RATE_SOURCE"`
+	FXRefreshInterval           time.Duration `mapstructure:"FX_REFRESH_INTERVAL"`
+	FXRateMaxAge                time.Duration `mapstructure:"FX_RATE_MAX_AGE"`
+	Environment                 string        `mapstructure:"ENVIRONMENT"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -33,3 +51,33 @@ func LoadConfig(path string) (config Config, err error) {
 
 	return
 }
+
+var (
+	watchOnce      sync.Once
+	watchMu        sync.Mutex
+	configWatchers []func(Config, error)
+)
+
+// WatchConfig calls onChange with the reloaded config every time the config
+// file loaded by LoadConfig changes, so that settings such as the token keys
+// can change without a restart
+func WatchConfig(onChange func(config Config, err error)) {
+	watchMu.Lock()
+	configWatchers = append(configWatchers, onChange)
+	watchMu.Unlock()
+
+	watchOnce.Do(func() {
+		viper.OnConfigChange(func(fsnotify.Event) {
+			var config Config
+			err := viper.Unmarshal(&config)
+
+			watchMu.Lock()
+			watchers := append([]func(Config, error){}, configWatchers...)
+			watchMu.Unlock()
+			for _, watcher := range watchers {
+				watcher(config, err)
+			}
+		})
+		viper.WatchConfig()
+	})
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+)
+
+// Currency is an ISO 4217 currency. Amounts are stored as integers in its
+// minor unit, e.g. cents, and MinorUnits is the number of decimals of the
+// major unit: 2 for USD, 0 for JPY. A disabled currency keeps working for the
+// accounts that already hold it, but no new account can be opened in it.
+type Currency struct {
+	Code       string
+	MinorUnits int32
+	Enabled    bool
+}
+
+// defaultCurrencies 与 000018_add_currencies 迁移的种子数据一致，启动时会被 currencies 表替换
+var defaultCurrencies = []Currency{
+	{Code: "CAD", MinorUnits: 2, Enabled: true},
+	{Code: "EUR", MinorUnits: 2, Enabled: true},
+	{Code: "GBP", MinorUnits: 2, Enabled: true},
+	{Code: "JPY", MinorUnits: 0, Enabled: true},
+	{Code: "USD", MinorUnits: 2, Enabled: true},
+}
+
+var (
+	currencyMu sync.RWMutex
+	currencies = indexCurrencies(defaultCurrencies)
+)
+
+func indexCurrencies(list []Currency) map[string]Currency {
+	index := make(map[string]Currency, len(list))
+	for _, c := range list {
+		index[c.Code] = c
+	}
+	return index
+}
+
+// SetCurrencies replaces the currency registry, normally with the rows of the
+// currencies table loaded at startup
+func SetCurrencies(list []Currency) {
+	index := indexCurrencies(list)
+
+	currencyMu.Lock()
+	defer currencyMu.Unlock()
+	currencies = index
+}
+
+// LookupCurrency returns the currency with the given code, enabled or not
+func LookupCurrency(code string) (Currency, bool) {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+
+	c, ok := currencies[code]
+	return c, ok
+}
+
+// SupportedCurrencies returns the sorted codes of the enabled currencies, the
+// ones new accounts can be opened in
+func SupportedCurrencies() []string {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+
+	codes := make([]string, 0, len(currencies))
+	for code, c := range currencies {
+		if c.Enabled {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	t.Cleanup(func() { SetCurrencies(defaultCurrencies) })
+
+	require.True(t, VaildatorCurrency("USD"))
+	require.False(t, VaildatorCurrency("CHF"))
+
+	SetCurrencies([]Currency{
+		{Code: "CHF", MinorUnits: 2, Enabled: true},
+		{Code: "USD", MinorUnits: 2, Enabled: false},
+		{Code: "KWD", MinorUnits: 3, Enabled: true},
+	})
+	require.True(t, VaildatorCurrency("CHF"))
+	require.False(t, VaildatorCurrency("EUR"))
+	require.True(t, VaildatorAccountCurrency("CHF"))
+
+	// 停用的货币不能开新账户，已有账户照常使用
+	require.True(t, VaildatorCurrency("USD"))
+	require.False(t, VaildatorAccountCurrency("USD"))
+	usd, ok := LookupCurrency("USD")
+	require.True(t, ok)
+	require.False(t, usd.Enabled)
+	require.Equal(t, []string{"CHF", "KWD"}, SupportedCurrencies())
+	require.Contains(t, SupportedCurrencies(), RandomCurrency())
+
+	currency, ok := LookupCurrency("KWD")
+	require.True(t, ok)
+	require.Equal(t, int32(3), currency.MinorUnits)
+}
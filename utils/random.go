@@ -35,7 +35,7 @@ func RandomOwnerName() string {
 }
 
 func RandomCurrency() string {
-	currencies := []string{"USD", "EUR", "CAD", "JPY", "GBP"}
+	currencies := SupportedCurrencies()
 	return currencies[RandomInt63(0, int64(len(currencies)-1))]
 }
 
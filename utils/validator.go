@@ -1,9 +1,15 @@
 package utils
 
+// VaildatorCurrency reports whether currency is a currency of the registry,
+// enabled or not, so accounts in a disabled currency keep working
 func VaildatorCurrency(currency string) bool {
-	switch currency {
-	case "USD", "EUR", "CAD", "JPY", "GBP":
-		return true
-	}
-	return false
+	_, ok := LookupCurrency(currency)
+	return ok
+}
+
+// VaildatorAccountCurrency reports whether a new account can be opened in
+// currency, i.e. it is in the registry and enabled
+func VaildatorAccountCurrency(currency string) bool {
+	c, ok := LookupCurrency(currency)
+	return ok && c.Enabled
 }
@@ -3,6 +3,11 @@ package val
 import (
 	"fmt"
 	"regexp"
+	"time"
+
+	db "github.com/zjr71163356/simplebank/db/sqlc"
+	"github.com/zjr71163356/simplebank/pagination"
+	"github.com/zjr71163356/simplebank/utils"
 )
 
 const (
@@ -19,6 +24,10 @@ func ValidateUsername(username string) error {
 	if !matchRegex(usernameRegex, username) {
 		return fmt.Errorf("用户名不符合要求: %s", username)
 	}
+	// 银行账户的所有者由迁移创建，客户不能注册同名用户
+	if db.IsBankAccount(username) {
+		return fmt.Errorf("用户名已被系统保留: %s", username)
+	}
 	return nil
 }
 
@@ -49,6 +58,75 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
+func ValidateCurrency(currency string) error {
+	if !utils.VaildatorCurrency(currency) {
+		return fmt.Errorf("unsupported currency: %s", currency)
+	}
+	return nil
+}
+
+// ValidateAccountCurrency also rejects disabled currencies, which only
+// existing accounts may use
+func ValidateAccountCurrency(currency string) error {
+	if err := ValidateCurrency(currency); err != nil {
+		return err
+	}
+	if !utils.VaildatorAccountCurrency(currency) {
+		return fmt.Errorf("currency is disabled for new accounts: %s", currency)
+	}
+	return nil
+}
+
+func ValidateID(id int64) error {
+	if id < 1 {
+		return fmt.Errorf("id must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateAmount(amount int64) error {
+	if amount < 1 {
+		return fmt.Errorf("amount must be a positive integer")
+	}
+	return nil
+}
+
+func ValidatePageID(pageID int32) error {
+	if pageID < 1 {
+		return fmt.Errorf("page id must be at least 1")
+	}
+	return nil
+}
+
+func ValidatePageSize(pageSize int32) error {
+	if pageSize < 5 || pageSize > 10 {
+		return fmt.Errorf("page size should be between 5 and 10")
+	}
+	return nil
+}
+
+// ValidateKeysetPageSize accepts 0, which stands for the default page size
+func ValidateKeysetPageSize(pageSize int32) error {
+	if pageSize < 0 || pageSize > pagination.MaxPageSize {
+		return fmt.Errorf("page size should be between 1 and %d, or 0 for the default", pagination.MaxPageSize)
+	}
+	return nil
+}
+
+func ValidateTimeRange(start time.Time, end time.Time, maxRange time.Duration) error {
+	if !start.Before(end) {
+		return fmt.Errorf("start time must be before end time")
+	}
+	if end.Sub(start) > maxRange {
+		return fmt.Errorf("time range must not exceed %s", maxRange)
+	}
+	return nil
+}
+
+func ValidateIdempotencyKey(key string) error {
+	return VaildateStringLen(key, 1, 255)
+}
+
 func matchRegex(pattern, value string) bool {
 	re := regexp.MustCompile(pattern)
 	return re.MatchString(value)